@@ -3,9 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime/debug"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/benfiola/versionctl/internal/versionctl"
@@ -14,10 +19,29 @@ import (
 
 type ContextOpts struct{}
 
-// Creates a root logger for the application.
+// Reads build info embedded in the binary (overridable for testing)
+var readBuildInfo = debug.ReadBuildInfo
+
+// Gets the tool version.
+// Prefers the version embedded in the binary - falls back to the module version within the build info (e.g., for 'go install'-ed binaries).
+// Returns '(devel)' if neither are available.
+func getToolVersion() string {
+	v := strings.TrimSpace(versionctl.VersionctlVersion)
+	if v != "" {
+		return v
+	}
+	bi, ok := readBuildInfo()
+	if ok && bi.Main.Version != "" {
+		return strings.TrimPrefix(bi.Main.Version, "v")
+	}
+	return "(devel)"
+}
+
+// Creates a root logger for the application that writes to the provided writer.
 // Accepts a logging level 'error' | 'warn' | 'info' | 'debug'
-// Returns an error if the logging level is invalid
-func createLogger(lls string) (*slog.Logger, error) {
+// Accepts a logging format 'text' | 'json'
+// Returns an error if the logging level or format is invalid
+func createLogger(lls string, lf string, w io.Writer) (*slog.Logger, error) {
 	if lls == "" {
 		lls = "error"
 	}
@@ -34,10 +58,19 @@ func createLogger(lls string) (*slog.Logger, error) {
 	default:
 		return nil, fmt.Errorf("invalid log level %s", lls)
 	}
-	l := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	ho := &slog.HandlerOptions{
 		Level: ll,
-	}))
-	return l, nil
+	}
+	var h slog.Handler
+	switch lf {
+	case "", "text":
+		h = slog.NewTextHandler(w, ho)
+	case "json":
+		h = slog.NewJSONHandler(w, ho)
+	default:
+		return nil, fmt.Errorf("invalid log format %s", lf)
+	}
+	return slog.New(h), nil
 }
 
 // Loads a config from the provided path.  If the path is
@@ -63,15 +96,132 @@ func loadConfig(p string) (*versionctl.Config, error) {
 	return cfg, nil
 }
 
-func main() {
-	err := (&cli.App{
+// Prints a version to the application's writer.
+// Strips metadata from the version if the 'no-metadata' flag is set.
+func printVersion(c *cli.Context, v versionctl.Version) {
+	if c.Bool("no-metadata") {
+		v.Metadata = ""
+	}
+	fmt.Fprintf(c.App.Writer, "%s", v.String(""))
+}
+
+// Verifies the signature of the current head if the 'require-signed' flag is set.
+// The flag's value is the path to an armored keyring.
+func verifyHeadSignature(c *cli.Context, a *versionctl.Analyzer) error {
+	p := c.String("require-signed")
+	if p == "" {
+		return nil
+	}
+	kr, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	return a.VerifyHeadSignature(string(kr))
+}
+
+// Returns true if the writer is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Prints a single check result to the application's writer.
+// Colors the result (green/red) if the writer is a terminal.
+func printCheckResult(c *cli.Context, cr versionctl.CheckResult) {
+	s := "FAIL"
+	cc := "31"
+	if cr.OK {
+		s = "OK"
+		cc = "32"
+	}
+	if isTerminal(c.App.Writer) {
+		s = fmt.Sprintf("\x1b[%sm%s\x1b[0m", cc, s)
+	}
+	fmt.Fprintf(c.App.Writer, "[%s] %s: %s\n", s, cr.Name, cr.Message)
+}
+
+// Checks that the configuration defines tags for its parser(s)
+func checkParserTags(cfg *versionctl.Config) versionctl.CheckResult {
+	cr := versionctl.CheckResult{Name: "parser"}
+	if cfg.Parser == "chain" {
+		for i, ps := range cfg.Parsers {
+			if len(ps.Tags) == 0 {
+				cr.Message = fmt.Sprintf("chain parser %d has no tags configured", i)
+				return cr
+			}
+		}
+		if len(cfg.Parsers) == 0 {
+			cr.Message = "chain parser has no parsers configured"
+			return cr
+		}
+		cr.OK = true
+		cr.Message = fmt.Sprintf("%d chain parser(s) configured", len(cfg.Parsers))
+		return cr
+	}
+	if len(cfg.Tags) == 0 {
+		cr.Message = "no tags configured"
+		return cr
+	}
+	cr.OK = true
+	cr.Message = fmt.Sprintf("%d tag(s) configured", len(cfg.Tags))
+	return cr
+}
+
+// Appends 'key=value' outputs to the file referenced by the GITHUB_OUTPUT environment variable (e.g., within GitHub Actions).
+// Does nothing if the environment variable is unset.
+func writeGitHubOutput(kvs ...string) error {
+	p := os.Getenv("GITHUB_OUTPUT")
+	if p == "" {
+		return nil
+	}
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for i := 0; i+1 < len(kvs); i += 2 {
+		_, err = fmt.Fprintf(f, "%s=%s\n", kvs[i], kvs[i+1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the GitHub outputs describing a version (see [writeGitHubOutput]).
+// Strips metadata from the version if the 'no-metadata' flag is set.
+func versionOutputs(c *cli.Context, v versionctl.Version) []string {
+	if c.Bool("no-metadata") {
+		v.Metadata = ""
+	}
+	p := v.Prerelease != (versionctl.Prerelease{})
+	return []string{"version", v.String(""), "is_prerelease", strconv.FormatBool(p)}
+}
+
+// Creates the command-line application.
+func newApp() *cli.App {
+	return &cli.App{
 		Usage: "a version management tool",
 		Before: func(c *cli.Context) error {
 			cfg, err := loadConfig(c.String("config"))
 			if err != nil {
 				return err
 			}
-			l, err := createLogger(c.String("log-level"))
+			if c.Bool("strict") {
+				cfg.Strict = true
+			}
+			lls := c.String("log-level")
+			if c.Bool("quiet") {
+				lls = "error"
+			}
+			l, err := createLogger(lls, c.String("log-format"), c.App.ErrWriter)
 			if err != nil {
 				return err
 			}
@@ -87,22 +237,91 @@ func main() {
 				Name:  "config",
 				Usage: "path to a configuration file",
 			},
+			&cli.StringFlag{
+				Name:  "log-format",
+				Usage: "logging format ('text' or 'json')",
+			},
 			&cli.StringFlag{
 				Name:  "log-level",
 				Usage: "logging verbosity level",
 			},
+			&cli.BoolFlag{
+				Name:  "quiet",
+				Usage: "only log errors (overrides --log-level)",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "treat warnings (e.g., shallow clones) as errors",
+			},
 		},
 		Commands: []*cli.Command{
 			{
 				Name:      "convert",
 				Usage:     "convert a version into other formats",
 				ArgsUsage: "[value] [format]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "extract",
+						Usage: "search the value for an embedded version (e.g., 'git describe' output)",
+					},
+					&cli.StringFlag{
+						Name:  "field",
+						Usage: "print a single version component instead (e.g., major, prerelease.count, metadata)",
+					},
+				},
 				Action: func(c *cli.Context) error {
+					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
+					if !ok {
+						return fmt.Errorf("context has invalid opts")
+					}
 					v := c.Args().Get(0)
 					f := c.Args().Get(1)
-					vn, err := versionctl.NewVersion(v)
-					if err != nil {
-						return err
+					var vn versionctl.Version
+					var err error
+					if c.Bool("extract") {
+						vn, err = versionctl.ExtractVersion(v)
+						if err != nil {
+							return err
+						}
+					} else {
+						vn, err = versionctl.NewVersion(v)
+						if err != nil {
+							// fall back to dotnet-style versions (e.g., '1.2.3.4')
+							dvn, derr := versionctl.NewDotnetVersion(v)
+							if derr != nil {
+								return err
+							}
+							vn = dvn
+						}
+					}
+					fn := c.String("field")
+					if fn != "" {
+						fv, err := vn.Field(fn)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(c.App.Writer, "%s", fv)
+						return nil
+					}
+					f = versionctl.NormalizeFormat(f)
+					if vn.Revision != 0 && f != "dotnet" {
+						// only the dotnet format has a fourth component
+						return fmt.Errorf("version %s has a revision - only the dotnet format can represent it", vn.String("dotnet"))
+					}
+					for n, fp := range o.Config.Formats {
+						if versionctl.NormalizeFormat(n) == f {
+							// custom format
+							fmt.Fprintf(c.App.Writer, "%s", vn.Format(fp))
+							return nil
+						}
+					}
+					if f != "" && !slices.Contains(versionctl.Formats, f) {
+						return fmt.Errorf("unknown format %s", f)
+					}
+					if f == "git" && o.Config.TagPrefix != "" {
+						// the 'git' format uses the configured tag prefix
+						fmt.Fprintf(c.App.Writer, "%s", vn.Tag(o.Config.TagPrefix))
+						return nil
 					}
 					fmt.Fprintf(c.App.Writer, "%s", vn.String(f))
 					return nil
@@ -111,6 +330,12 @@ func main() {
 			{
 				Name:  "current",
 				Usage: "print the current version",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "no-metadata",
+						Usage: "strip build metadata from the version",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
 					if !ok {
@@ -124,27 +349,228 @@ func main() {
 					if err != nil {
 						return err
 					}
-					fmt.Fprintf(c.App.Writer, "%s", v.String(""))
+					printVersion(c, v)
+					return writeGitHubOutput(versionOutputs(c, v)...)
+				},
+			},
+			{
+				Name:      "diff-range",
+				Usage:     "print the version change implied by the commits between two revisions",
+				ArgsUsage: "[from] [to]",
+				Action: func(c *cli.Context) error {
+					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
+					if !ok {
+						return fmt.Errorf("context has invalid opts")
+					}
+					a, err := versionctl.New(o)
+					if err != nil {
+						return err
+					}
+					vc, err := a.ChangeBetween(c.Args().Get(0), c.Args().Get(1))
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "%s", vc.Value)
+					return nil
+				},
+			},
+			{
+				Name:  "doctor",
+				Usage: "check that the repository and configuration are usable (read-only)",
+				Action: func(c *cli.Context) error {
+					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
+					if !ok {
+						return fmt.Errorf("context has invalid opts")
+					}
+					crs := []versionctl.CheckResult{checkParserTags(o.Config)}
+					a, err := versionctl.New(o)
+					if err != nil {
+						crs = append(crs, versionctl.CheckResult{Name: "repository", Message: err.Error()})
+					} else {
+						crs = append(crs, versionctl.CheckResult{Name: "repository", OK: true, Message: "repository is usable"})
+						crs = append(crs, a.Check()...)
+					}
+					f := 0
+					for _, cr := range crs {
+						printCheckResult(c, cr)
+						if !cr.OK {
+							f += 1
+						}
+					}
+					if f > 0 {
+						return fmt.Errorf("%d check(s) failed", f)
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "list all version tags (highest first)",
+				Action: func(c *cli.Context) error {
+					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
+					if !ok {
+						return fmt.Errorf("context has invalid opts")
+					}
+					a, err := versionctl.New(o)
+					if err != nil {
+						return err
+					}
+					tvs, err := a.ListVersions()
+					if err != nil {
+						return err
+					}
+					for _, tv := range tvs {
+						fmt.Fprintf(c.App.Writer, "%s %s\n", tv.Tag, tv.Version.String(""))
+					}
 					return nil
 				},
 			},
 			{
 				Name:  "next",
 				Usage: "print the next version",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "branch",
+						Usage: "(with --target) the branch to preview as merged (default: HEAD)",
+					},
+					&cli.BoolFlag{
+						Name:  "build-on-unchanged",
+						Usage: "(release rules) print the last release with the commit's short hash as metadata if the version is unchanged",
+					},
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "(with --set) allow a version that isn't greater than the current version",
+					},
+					&cli.StringFlag{
+						Name:  "metadata",
+						Usage: "build metadata to attach to the version (overrides rule metadata)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-metadata",
+						Usage: "strip build metadata from the version (overrides --metadata)",
+					},
+					&cli.StringFlag{
+						Name:  "require-signed",
+						Usage: "fail unless the current commit is signed by a key within the provided armored keyring file",
+					},
+					&cli.BoolFlag{
+						Name:  "reuse-release",
+						Usage: "print the existing release version if the current commit is already released",
+					},
+					&cli.StringFlag{
+						Name:  "set",
+						Usage: "use the provided version as the next version (bypasses commit analysis)",
+					},
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "print the next version of the target branch as if --branch were merged into it (e.g., pull request previews)",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
 					if !ok {
 						return fmt.Errorf("context has invalid opts")
 					}
+					if c.Bool("build-on-unchanged") {
+						o.Config.BuildOnUnchanged = true
+					}
+					m := c.String("metadata")
+					if m != "" {
+						o.Transform = func(v versionctl.Version) (versionctl.Version, error) {
+							v.Metadata = versionctl.SanitizeMetadata(m)
+							return v, nil
+						}
+					}
 					a, err := versionctl.New(o)
 					if err != nil {
 						return err
 					}
-					v, err := a.GetNextVersion()
+					err = verifyHeadSignature(c, a)
 					if err != nil {
 						return err
 					}
-					fmt.Fprintf(c.App.Writer, "%s", v.String(""))
+					s := c.String("set")
+					if s != "" {
+						v, err := a.OverrideNextVersion(s, c.Bool("force"))
+						if err != nil {
+							return err
+						}
+						printVersion(c, v)
+						return writeGitHubOutput(append(versionOutputs(c, v), "changed", "true")...)
+					}
+					tb := c.String("target")
+					mb := c.String("branch")
+					if tb == "" && mb != "" {
+						return fmt.Errorf("--branch requires --target")
+					}
+					if tb != "" {
+						if mb == "" {
+							mb = "HEAD"
+						}
+						v, ch, err := a.GetMergePreviewVersionChanged(tb, mb)
+						if errors.Is(err, versionctl.ErrVersionUnchanged) {
+							werr := writeGitHubOutput("changed", "false")
+							if werr != nil {
+								return werr
+							}
+						}
+						if err != nil {
+							return err
+						}
+						printVersion(c, v)
+						return writeGitHubOutput(append(versionOutputs(c, v), "changed", strconv.FormatBool(ch))...)
+					}
+					if c.Bool("reuse-release") {
+						r, v, err := a.IsReleased()
+						if err != nil {
+							return err
+						}
+						if r {
+							printVersion(c, v)
+							return writeGitHubOutput(append(versionOutputs(c, v), "changed", "false")...)
+						}
+					}
+					v, ch, err := a.GetNextVersionChanged()
+					if errors.Is(err, versionctl.ErrVersionUnchanged) {
+						werr := writeGitHubOutput("changed", "false")
+						if werr != nil {
+							return werr
+						}
+					}
+					if err != nil {
+						return err
+					}
+					printVersion(c, v)
+					return writeGitHubOutput(append(versionOutputs(c, v), "changed", strconv.FormatBool(ch))...)
+				},
+			},
+			{
+				Name:  "promote",
+				Usage: "print the release version of the current prerelease version",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "require-signed",
+						Usage: "fail unless the current commit is signed by a key within the provided armored keyring file",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
+					if !ok {
+						return fmt.Errorf("context has invalid opts")
+					}
+					a, err := versionctl.New(o)
+					if err != nil {
+						return err
+					}
+					err = verifyHeadSignature(c, a)
+					if err != nil {
+						return err
+					}
+					v, err := a.GetPromotedVersion()
+					if err != nil {
+						return err
+					}
+					printVersion(c, v)
 					return nil
 				},
 			},
@@ -152,13 +578,74 @@ func main() {
 				Name:      "set",
 				Usage:     "set version field for known files",
 				ArgsUsage: "[file] [version]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "allow-invalid",
+						Usage: "write the version even if it isn't a valid semantic version",
+					},
+					&cli.BoolFlag{
+						Name:  "create",
+						Usage: "create the file if it doesn't exist",
+					},
+					&cli.StringFlag{
+						Name:  "dockerfile-directive",
+						Usage: "(Dockerfile) the directive holding the version (default: ARG) - e.g., LABEL",
+					},
+					&cli.StringFlag{
+						Name:  "dockerfile-key",
+						Usage: "(Dockerfile) the directive key holding the version (default: VERSION) - e.g., org.opencontainers.image.version",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					f := c.Args().Get(0)
 					v := c.Args().Get(1)
-					err := versionctl.SetVersion(f, v)
+					err := versionctl.SetVersionWithOpts(f, v, &versionctl.SetVersionOpts{
+						AllowInvalid:        c.Bool("allow-invalid"),
+						Create:              c.Bool("create"),
+						DockerfileDirective: c.String("dockerfile-directive"),
+						DockerfileKey:       c.String("dockerfile-key"),
+					})
+					if err != nil {
+						return err
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "verify",
+				Usage: "verify that a known file declares the next version",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Usage:    "path to a known file (e.g., package.json, pyproject.toml)",
+						Required: true,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					o, ok := c.Context.Value(ContextOpts{}).(*versionctl.Opts)
+					if !ok {
+						return fmt.Errorf("context has invalid opts")
+					}
+					a, err := versionctl.New(o)
 					if err != nil {
 						return err
 					}
+					v, err := a.GetNextVersion()
+					if err != nil {
+						return err
+					}
+					f := c.String("file")
+					fvs, err := versionctl.GetVersion(f)
+					if err != nil {
+						return err
+					}
+					fv, err := versionctl.NewVersion(fvs)
+					if err != nil {
+						return err
+					}
+					if v.Compare(fv) != 0 {
+						return fmt.Errorf("version mismatch: %s declares %s, next version is %s", f, fv.String(""), v.String(""))
+					}
 					return nil
 				},
 			},
@@ -166,13 +653,16 @@ func main() {
 				Name:  "version",
 				Usage: "print the tool version",
 				Action: func(c *cli.Context) error {
-					v := strings.TrimSpace(versionctl.VersionctlVersion)
-					fmt.Fprintf(c.App.Writer, "%s", v)
+					fmt.Fprintf(c.App.Writer, "%s", getToolVersion())
 					return nil
 				},
 			},
 		},
-	}).Run(os.Args)
+	}
+}
+
+func main() {
+	err := newApp().Run(os.Args)
 
 	code := 0
 	if err != nil {
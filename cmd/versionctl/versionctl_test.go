@@ -0,0 +1,884 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/benfiola/versionctl/internal/versionctl"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+type TestRepo struct {
+	*git.Repository
+	t testing.TB
+}
+
+// Helper method to create a git repo and change the working directory into it.
+func createGitRepo(t testing.TB) *TestRepo {
+	t.Helper()
+	require := require.New(t)
+	wd, err := os.Getwd()
+	require.Nil(err)
+	d := t.TempDir()
+	r, err := git.PlainInit(d, false)
+	require.Nil(err)
+	err = os.Chdir(d)
+	require.Nil(err)
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+	// avoid writing to the outputs of the surrounding workflow (e.g., when tests run within GitHub Actions)
+	t.Setenv("GITHUB_OUTPUT", "")
+	return &TestRepo{
+		Repository: r,
+		t:          t,
+	}
+}
+
+// Helper method to create a git commit with the provided message
+func (r *TestRepo) createGitCommit(message string) string {
+	r.t.Helper()
+	require := require.New(r.t)
+	wt, err := r.Worktree()
+	require.Nil(err)
+	h, err := wt.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "author", Email: "email", When: time.Now()}})
+	require.Nil(err)
+	return h.String()
+}
+
+// Helper method to create a git commit signed with the provided key
+func (r *TestRepo) createSignedGitCommit(message string, key *openpgp.Entity) string {
+	r.t.Helper()
+	require := require.New(r.t)
+	wt, err := r.Worktree()
+	require.Nil(err)
+	h, err := wt.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "author", Email: "email", When: time.Now()}, SignKey: key})
+	require.Nil(err)
+	return h.String()
+}
+
+// Helper method to create a PGP key and write its armored public keyring to a file within the working directory.
+func createPGPKeyring(t testing.TB, name string) *openpgp.Entity {
+	t.Helper()
+	require := require.New(t)
+	e, err := openpgp.NewEntity("author", "", "email", nil)
+	require.Nil(err)
+	b := &bytes.Buffer{}
+	w, err := armor.Encode(b, openpgp.PublicKeyType, nil)
+	require.Nil(err)
+	err = e.Serialize(w)
+	require.Nil(err)
+	err = w.Close()
+	require.Nil(err)
+	writeFile(t, name, b.String())
+	return e
+}
+
+// Helper method to create a git tag at the current head.
+func (r *TestRepo) createGitTag(name string) {
+	r.t.Helper()
+	require := require.New(r.t)
+	h, err := r.Head()
+	require.Nil(err)
+	_, err = r.CreateTag(name, h.Hash(), nil)
+	require.Nil(err)
+}
+
+// Helper method to checkout a git branch - creating it if it doesn't exist.
+func (r *TestRepo) checkoutGitBranch(name string) {
+	r.t.Helper()
+	require := require.New(r.t)
+	_, err := r.ResolveRevision(plumbing.Revision(name))
+	c := err != nil
+	wt, err := r.Worktree()
+	require.Nil(err)
+	err = wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(name), Create: c})
+	require.Nil(err)
+}
+
+// Helper method to write a file within the working directory.
+func writeFile(t testing.TB, name string, content string) {
+	t.Helper()
+	require := require.New(t)
+	err := os.WriteFile(name, []byte(content), 0o644)
+	require.Nil(err)
+}
+
+// Helper method to run the application with the provided arguments.
+// Returns the application's stdout.
+func runApp(t testing.TB, args ...string) (string, error) {
+	t.Helper()
+	o, _, err := runAppWithStderr(t, args...)
+	return o, err
+}
+
+// Helper method to run the application with the provided arguments.
+// Returns the application's stdout and stderr.
+func runAppWithStderr(t testing.TB, args ...string) (string, string, error) {
+	t.Helper()
+	o := &bytes.Buffer{}
+	e := &bytes.Buffer{}
+	a := newApp()
+	a.Writer = o
+	a.ErrWriter = e
+	err := a.Run(append([]string{"versionctl"}, args...))
+	return o.String(), e.String(), err
+}
+
+func TestNext(t *testing.T) {
+	t.Run("prints next version", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+
+		o, err := runApp(t, "next")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1+master", o)
+	})
+
+	t.Run("require signed, signed commit", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		e := createPGPKeyring(t, "keyring.asc")
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createSignedGitCommit("fix: commit", e)
+
+		o, err := runApp(t, "next", "--require-signed", "keyring.asc")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1+master", o)
+	})
+
+	t.Run("require signed, unsigned commit", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		createPGPKeyring(t, "keyring.asc")
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		h := r.createGitCommit("fix: commit")
+
+		_, err := runApp(t, "next", "--require-signed", "keyring.asc")
+
+		require.ErrorContains(err, fmt.Sprintf("commit %s is not signed by a trusted key", h))
+	})
+
+	t.Run("require signed, commit signed by untrusted key", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		createPGPKeyring(t, "keyring.asc")
+		e := createPGPKeyring(t, "other.asc")
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		h := r.createSignedGitCommit("fix: commit", e)
+
+		_, err := runApp(t, "next", "--require-signed", "keyring.asc")
+
+		require.ErrorContains(err, fmt.Sprintf("commit %s is not signed by a trusted key", h))
+	})
+
+	t.Run("previews merge into target", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.checkoutGitBranch("main")
+		r.checkoutGitBranch("pr/123")
+		r.createGitCommit("feat: commit")
+
+		o, err := runApp(t, "next", "--target", "main", "--branch", "pr/123")
+
+		require.Nil(err)
+		require.Equal("0.2.0", o)
+	})
+
+	t.Run("previews current branch into target", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.checkoutGitBranch("main")
+		r.checkoutGitBranch("pr/123")
+		r.createGitCommit("fix: commit")
+
+		o, err := runApp(t, "next", "--target", "main")
+
+		require.Nil(err)
+		require.Equal("0.1.1", o)
+	})
+
+	t.Run("branch requires target", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+
+		_, err := runApp(t, "next", "--branch", "pr/123")
+
+		require.ErrorContains(err, "--branch requires --target")
+	})
+
+	t.Run("metadata overrides rule metadata", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+
+		o, err := runApp(t, "next", "--metadata", "build.5")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1+build.5", o)
+	})
+
+	t.Run("no metadata strips rule metadata", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+
+		o, err := runApp(t, "next", "--no-metadata")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1", o)
+	})
+
+	t.Run("reuse release short-circuits on released head", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+		r.createGitTag("v0.1.1")
+
+		o, err := runApp(t, "next", "--reuse-release")
+
+		require.Nil(err)
+		require.Equal("0.1.1", o)
+	})
+
+	t.Run("build on unchanged", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		h := r.createGitCommit("chore: commit")
+		writeFile(t, "config.json", `{"rules": [{"branch": "master"}], "tags": {"fix:": "patch"}}`)
+
+		o, err := runApp(t, "--config", "config.json", "next", "--build-on-unchanged")
+
+		require.Nil(err)
+		require.Equal("0.1.0+"+h[:7], o)
+	})
+
+	t.Run("released head without reuse release", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+		r.createGitTag("v0.1.1")
+
+		_, err := runApp(t, "next")
+
+		require.ErrorContains(err, "version unchanged")
+	})
+}
+
+func TestQuiet(t *testing.T) {
+	t.Run("logs to stderr", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+
+		o, e, err := runAppWithStderr(t, "--log-level", "info", "next")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1+master", o)
+		require.Contains(e, "level=INFO")
+	})
+
+	t.Run("quiet suppresses logs", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+
+		o, e, err := runAppWithStderr(t, "--quiet", "--log-level", "debug", "next")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1+master", o)
+		require.Equal("", e)
+	})
+}
+
+func TestLogFormat(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+
+		_, e, err := runAppWithStderr(t, "--log-format", "json", "--log-level", "info", "next")
+
+		require.Nil(err)
+		l := strings.Split(strings.TrimSpace(e), "\n")[0]
+		d := map[string]any{}
+		err = json.Unmarshal([]byte(l), &d)
+		require.Nil(err)
+		require.Equal("INFO", d["level"])
+		require.Equal("analyzer", d["name"])
+		require.Equal("branch: master", d["msg"])
+		require.Contains(d, "time")
+	})
+
+	t.Run("fails on invalid format", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := runApp(t, "--log-format", "xml", "version")
+
+		require.ErrorContains(err, "invalid log format xml")
+	})
+}
+
+func TestCurrent(t *testing.T) {
+	t.Run("prints current version", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0-rc.1+meta")
+
+		o, err := runApp(t, "current")
+
+		require.Nil(err)
+		require.Equal("0.1.0-rc.1+meta", o)
+	})
+
+	t.Run("no metadata strips metadata", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0-rc.1+meta")
+
+		o, err := runApp(t, "current", "--no-metadata")
+
+		require.Nil(err)
+		require.Equal("0.1.0-rc.1", o)
+	})
+}
+
+func TestPromote(t *testing.T) {
+	t.Run("promotes prerelease", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v1.2.0-rc.3")
+
+		o, err := runApp(t, "promote")
+
+		require.Nil(err)
+		require.Equal("1.2.0", o)
+	})
+
+	t.Run("fails on release", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v1.2.0")
+
+		_, err := runApp(t, "promote")
+
+		require.ErrorContains(err, "current version 1.2.0 is not a prerelease")
+	})
+
+	t.Run("require signed, signed commit", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		e := createPGPKeyring(t, "keyring.asc")
+		r.createSignedGitCommit("initial", e)
+		r.createGitTag("v1.2.0-rc.3")
+
+		o, err := runApp(t, "promote", "--require-signed", "keyring.asc")
+
+		require.Nil(err)
+		require.Equal("1.2.0", o)
+	})
+
+	t.Run("require signed, unsigned commit", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		createPGPKeyring(t, "keyring.asc")
+		h := r.createGitCommit("initial")
+		r.createGitTag("v1.2.0-rc.3")
+
+		_, err := runApp(t, "promote", "--require-signed", "keyring.asc")
+
+		require.ErrorContains(err, fmt.Sprintf("commit %s is not signed by a trusted key", h))
+	})
+}
+
+func TestNextSet(t *testing.T) {
+	t.Run("forward override", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+
+		o, err := runApp(t, "next", "--set", "1.0.0")
+
+		require.Nil(err)
+		require.Equal("1.0.0", o)
+	})
+
+	t.Run("rejects backward override", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+
+		_, err := runApp(t, "next", "--set", "0.0.1")
+
+		require.ErrorContains(err, "version 0.0.1 is not greater than current version 0.1.0")
+	})
+
+	t.Run("forces backward override", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+
+		o, err := runApp(t, "next", "--set", "0.0.1", "--force")
+
+		require.Nil(err)
+		require.Equal("0.0.1", o)
+	})
+}
+
+func TestDiffRange(t *testing.T) {
+	t.Run("prints change between tags", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v1.0.0")
+		r.createGitCommit("feat: commit")
+		r.createGitTag("v1.1.0")
+
+		o, err := runApp(t, "diff-range", "v1.0.0", "v1.1.0")
+
+		require.Nil(err)
+		require.Equal("minor", o)
+	})
+}
+
+func TestConvert(t *testing.T) {
+	t.Run("git format uses default prefix", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "0.1.0", "git")
+
+		require.Nil(err)
+		require.Equal("v0.1.0", o)
+	})
+
+	t.Run("extracts embedded version", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "--extract", "v1.2.3-4-gabc123", "docker")
+
+		require.Nil(err)
+		require.Equal("1.2.3", o)
+	})
+
+	t.Run("rejects embedded version without extract", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := runApp(t, "convert", "v1.2.3-4-gabc123", "docker")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("field", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "--field", "prerelease.count", "1.2.3-rc.4+meta")
+
+		require.Nil(err)
+		require.Equal("4", o)
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := runApp(t, "convert", "--field", "build", "1.2.3")
+
+		require.ErrorContains(err, "invalid field build")
+	})
+
+	t.Run("format is case-insensitive", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "0.1.0+meta", "Docker")
+
+		require.Nil(err)
+		require.Equal("0.1.0-meta", o)
+	})
+
+	t.Run("format tolerates whitespace", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "0.1.0", " GIT ")
+
+		require.Nil(err)
+		require.Equal("v0.1.0", o)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := runApp(t, "convert", "0.1.0", "rpm")
+
+		require.ErrorContains(err, "unknown format rpm")
+	})
+
+	t.Run("custom format", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+		writeFile(t, "config.json", `{"formats": {"compact": {"countSeparator": ""}}}`)
+
+		o, err := runApp(t, "--config", "config.json", "convert", "0.1.0-rc.1", "Compact")
+
+		require.Nil(err)
+		require.Equal("0.1.0-rc1", o)
+	})
+
+	t.Run("dotnet format", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "1.2.3.4", "dotnet")
+
+		require.Nil(err)
+		require.Equal("1.2.3.4", o)
+	})
+
+	t.Run("fails on revision for three-part format", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := runApp(t, "convert", "1.2.3.4", "semver")
+
+		require.ErrorContains(err, "version 1.2.3.4 has a revision - only the dotnet format can represent it")
+	})
+
+	t.Run("dotnet version without revision converts to semver", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "convert", "1.2.3.0", "semver")
+
+		require.Nil(err)
+		require.Equal("1.2.3", o)
+	})
+
+	t.Run("git format uses configured tag prefix", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+		writeFile(t, "config.json", `{"tagPrefix": "release-"}`)
+
+		o, err := runApp(t, "--config", "config.json", "convert", "0.1.0", "git")
+
+		require.Nil(err)
+		require.Equal("release-0.1.0", o)
+		require.Equal("v", versionctl.GitTagPrefix)
+	})
+}
+
+func TestSet(t *testing.T) {
+	t.Run("creates missing file", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+
+		_, err := runApp(t, "set", "--create", "0.1.0", "package.json")
+
+		require.Nil(err)
+		b, err := os.ReadFile("package.json")
+		require.Nil(err)
+		require.Equal(`{"version":"0.1.0"}`, string(b))
+	})
+
+	t.Run("fails on invalid version", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+		writeFile(t, "package.json", `{"version": "0.1.0"}`)
+
+		_, err := runApp(t, "set", "0.2", "package.json")
+
+		require.ErrorContains(err, "invalid version string 0.2")
+	})
+
+	t.Run("sets prerelease version", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+		writeFile(t, "package.json", `{"version": "0.1.0"}`)
+
+		_, err := runApp(t, "set", "0.2.0-rc.1", "package.json")
+
+		require.Nil(err)
+		b, err := os.ReadFile("package.json")
+		require.Nil(err)
+		require.Equal(`{"version":"0.2.0-rc.1"}`, string(b))
+	})
+
+	t.Run("sets invalid version when allowed", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+		writeFile(t, "package.json", `{"version": "0.1.0"}`)
+
+		_, err := runApp(t, "set", "--allow-invalid", "0.2", "package.json")
+
+		require.Nil(err)
+		b, err := os.ReadFile("package.json")
+		require.Nil(err)
+		require.Equal(`{"version":"0.2"}`, string(b))
+	})
+
+	t.Run("sets Dockerfile label", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+		writeFile(t, "Dockerfile", "FROM alpine\nLABEL org.opencontainers.image.version=\"0.1.0\"\n")
+
+		_, err := runApp(t, "set", "--dockerfile-directive", "LABEL", "--dockerfile-key", "org.opencontainers.image.version", "0.2.0", "Dockerfile")
+
+		require.Nil(err)
+		b, err := os.ReadFile("Dockerfile")
+		require.Nil(err)
+		require.Equal("FROM alpine\nLABEL org.opencontainers.image.version=\"0.2.0\"\n", string(b))
+	})
+
+	t.Run("fails on missing file without create", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+
+		_, err := runApp(t, "set", "0.1.0", "package.json")
+
+		require.ErrorIs(err, os.ErrNotExist)
+	})
+}
+
+func TestVersion(t *testing.T) {
+	t.Run("prints embedded version", func(t *testing.T) {
+		require := require.New(t)
+
+		o, err := runApp(t, "version")
+
+		require.Nil(err)
+		require.Equal(strings.TrimSpace(versionctl.VersionctlVersion), o)
+		require.NotEqual("", o)
+	})
+
+	t.Run("falls back to build info", func(t *testing.T) {
+		require := require.New(t)
+		v := versionctl.VersionctlVersion
+		rbi := readBuildInfo
+		t.Cleanup(func() {
+			versionctl.VersionctlVersion = v
+			readBuildInfo = rbi
+		})
+		versionctl.VersionctlVersion = ""
+		readBuildInfo = func() (*debug.BuildInfo, bool) {
+			return &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, true
+		}
+
+		o, err := runApp(t, "version")
+
+		require.Nil(err)
+		require.Equal("1.2.3", o)
+	})
+
+	t.Run("falls back to devel", func(t *testing.T) {
+		require := require.New(t)
+		v := versionctl.VersionctlVersion
+		rbi := readBuildInfo
+		t.Cleanup(func() {
+			versionctl.VersionctlVersion = v
+			readBuildInfo = rbi
+		})
+		versionctl.VersionctlVersion = ""
+		readBuildInfo = func() (*debug.BuildInfo, bool) {
+			return nil, false
+		}
+
+		o, err := runApp(t, "version")
+
+		require.Nil(err)
+		require.Equal("(devel)", o)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("matching manifest", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+		writeFile(t, "package.json", `{"version": "0.1.1-alpha.1"}`)
+
+		_, err := runApp(t, "verify", "--file", "package.json")
+
+		require.Nil(err)
+	})
+
+	t.Run("mismatching manifest", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+		writeFile(t, "package.json", `{"version": "0.1.0"}`)
+
+		_, err := runApp(t, "verify", "--file", "package.json")
+
+		require.ErrorContains(err, "version mismatch: package.json declares 0.1.0, next version is 0.1.1-alpha.1+master")
+	})
+}
+
+func TestDoctor(t *testing.T) {
+	t.Run("well-formed repo", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+
+		o, err := runApp(t, "doctor")
+
+		require.Nil(err)
+		require.Contains(o, "[OK] parser: ")
+		require.Contains(o, "[OK] repository: ")
+		require.Contains(o, "[OK] commits: ")
+		require.Contains(o, "[OK] rule: branch master matches rule")
+		require.Contains(o, "[OK] tags: 1 of 1 tag(s) are versions (current: 0.1.0)")
+		require.NotContains(o, "[FAIL]")
+	})
+
+	t.Run("repo without tags", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+
+		o, err := runApp(t, "doctor")
+
+		require.Nil(err)
+		require.Contains(o, "[OK] tags: no version tags (of 0 tag(s)) - versions start from 0.0.0")
+	})
+
+	t.Run("mis-configured repo", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		writeFile(t, "config.json", `{"rules": [{"branch": "main"}]}`)
+
+		o, err := runApp(t, "--config", "config.json", "doctor")
+
+		require.ErrorContains(err, "2 check(s) failed")
+		require.Contains(o, "[FAIL] parser: no tags configured")
+		require.Contains(o, "[FAIL] rule: ")
+		require.Contains(o, "[OK] commits: ")
+	})
+
+	t.Run("repo without commits", func(t *testing.T) {
+		require := require.New(t)
+		createGitRepo(t)
+
+		o, err := runApp(t, "doctor")
+
+		require.NotNil(err)
+		require.Contains(o, "[FAIL] commits: ")
+	})
+}
+
+func TestGitHubOutput(t *testing.T) {
+	t.Run("next writes outputs", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.createGitCommit("fix: commit")
+		f := t.TempDir() + "/output"
+		writeFile(t, f, "existing=value\n")
+		t.Setenv("GITHUB_OUTPUT", f)
+
+		o, err := runApp(t, "next")
+
+		require.Nil(err)
+		require.Equal("0.1.1-alpha.1+master", o)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("existing=value\nversion=0.1.1-alpha.1+master\nis_prerelease=true\nchanged=true\n", string(b))
+	})
+
+	t.Run("next writes unchanged output", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		f := t.TempDir() + "/output"
+		t.Setenv("GITHUB_OUTPUT", f)
+
+		_, err := runApp(t, "next")
+
+		require.ErrorIs(err, versionctl.ErrVersionUnchanged)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("changed=false\n", string(b))
+	})
+
+	t.Run("next writes unchanged output when building on unchanged", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		r.checkoutGitBranch("main")
+		f := t.TempDir() + "/output"
+		t.Setenv("GITHUB_OUTPUT", f)
+
+		o, err := runApp(t, "next", "--build-on-unchanged")
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal(fmt.Sprintf("version=%s\nis_prerelease=false\nchanged=false\n", o), string(b))
+	})
+
+	t.Run("current writes outputs", func(t *testing.T) {
+		require := require.New(t)
+		r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v0.1.0")
+		f := t.TempDir() + "/output"
+		t.Setenv("GITHUB_OUTPUT", f)
+
+		o, err := runApp(t, "current")
+
+		require.Nil(err)
+		require.Equal("0.1.0", o)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("version=0.1.0\nis_prerelease=false\n", string(b))
+	})
+}
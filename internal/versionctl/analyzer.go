@@ -1,12 +1,14 @@
 package versionctl
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 )
 
 // Represents a rule match.
@@ -18,9 +20,11 @@ type RuleMatch struct {
 
 // A Rule matches a branch name with specific version change behavior
 type Rule struct {
-	Branch          string
-	PrereleaseToken string
-	Metadata        string
+	Branch          string `json:"branch"`
+	PrereleaseToken string `json:"prereleaseToken"`
+	Metadata        string `json:"buildMetadata"`
+	MinVersion      string `json:"minVersion"` // a floor for the next version (e.g., '2.0.0' for a 'release/2.x' branch)
+	MaxChange       string `json:"maxChange"`  // a cap for the version change (e.g., 'patch' for a 'release/1.2.x' branch)
 }
 
 // Matches a branch name to a given [Rule].
@@ -44,93 +48,396 @@ func (r Rule) Match(b string) (RuleMatch, error) {
 
 // An Analyzer uses local repository data alongside configured rules to manage software versions
 type Analyzer struct {
-	git    *Git
-	logger *slog.Logger
-	parser Parser
-	rules  []Rule
+	buildOnUnchanged   bool
+	excludeAuthors     []*regexp.Regexp
+	excludeTrailers    []string
+	git                *Git
+	includeTags        []*regexp.Regexp
+	logger             *slog.Logger
+	now                func() time.Time
+	parser             Parser
+	pathFilter         []*regexp.Regexp
+	prereleaseStart    int
+	rules              []Rule
+	scheme             string
+	strict             bool
+	tagPrefix          string
+	tagRemote          string
+	transform          func(Version) (Version, error)
+	useNotes           bool
+	zeroMajorSemantics bool
 }
 
 // Options to provide the analyzer constructor [NewAnalyzer]
 type AnalyzerOpts struct {
-	Git    *Git
-	Logger *slog.Logger
-	Parser Parser
-	Rules  []Rule
+	BuildOnUnchanged   bool     // when true, unchanged versions on release rules produce the last release with the head's short hash as metadata (rather than an error)
+	ExcludeAuthors     []string // regexes - commits whose author ('name <email>') matches are ignored
+	ExcludeTrailers    []string // commits containing a line starting with any of these trailers are ignored
+	Git                *Git
+	IncludeTags        []string // regexes - when non-empty, only matching tags are considered versions
+	Logger             *slog.Logger
+	Now                func() time.Time // returns the current time (defaults to [time.Now])
+	Parser             Parser
+	PathFilter         []string // globs - when non-empty, only commits touching matching paths contribute to version changes
+	PrereleaseStart    *int     // initial prerelease count for a new prerelease token (defaults to 1)
+	Rules              []Rule
+	Scheme             string                         // the versioning scheme - 'semver' | 'calver' (defaults to 'semver')
+	Strict             bool                           // when true, potential problems (e.g., shallow clones) are errors rather than warnings
+	TagPrefix          string                         // the prefix of version tags (defaults to [GitTagPrefix])
+	TagRemote          string                         // when set, repo-wide versions are sourced from this remote's tags (name or url)
+	Transform          func(Version) (Version, error) // post-processes the next version (invoked after rule metadata is applied)
+	UseNotes           bool                           // when true, commit notes ('refs/notes/commits') are parsed alongside commit messages
+	ZeroMajorSemantics bool                           // when true, changes to 0.x versions are downgraded (major -> minor, minor -> patch)
 }
 
 // Creates a new [Analyzer] from the provided [AnalyzerOpts].
+// Returns an error if any include tag or exclude author pattern is an invalid regex.
+// Returns an error if any path filter is an invalid glob.
+// Returns an error if the versioning scheme is invalid.
+// Returns an error if any rule min version is invalid.
+// Returns an error if multiple rules share a branch pattern (strict only - otherwise, logs a warning).
 func NewAnalyzer(o *AnalyzerOpts) (*Analyzer, error) {
 	l := o.Logger
 	if l == nil {
 		l = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
+	n := o.Now
+	if n == nil {
+		n = time.Now
+	}
+	s := o.Scheme
+	if s == "" {
+		s = "semver"
+	}
+	switch s {
+	case "calver", "semver":
+	default:
+		return nil, fmt.Errorf("invalid scheme %s", s)
+	}
+	eas := []*regexp.Regexp{}
+	for _, ea := range o.ExcludeAuthors {
+		eare, err := regexp.Compile(ea)
+		if err != nil {
+			return nil, err
+		}
+		eas = append(eas, eare)
+	}
+	its := []*regexp.Regexp{}
+	for _, it := range o.IncludeTags {
+		itre, err := regexp.Compile(it)
+		if err != nil {
+			return nil, err
+		}
+		its = append(its, itre)
+	}
+	pf := []*regexp.Regexp{}
+	for _, p := range o.PathFilter {
+		pre, err := compileGlob(p)
+		if err != nil {
+			return nil, err
+		}
+		pf = append(pf, pre)
+	}
+	tp := o.TagPrefix
+	if tp == "" {
+		tp = GitTagPrefix
+	}
+	ps := 1
+	if o.PrereleaseStart != nil {
+		ps = *o.PrereleaseStart
+	}
+	rbs := map[string]bool{}
+	for _, r := range o.Rules {
+		_, err := parseMinVersion(r)
+		if err != nil {
+			return nil, err
+		}
+		switch r.MaxChange {
+		case "", "major", "minor", "patch":
+		default:
+			return nil, fmt.Errorf("invalid max change for rule %s: %s", r.Branch, r.MaxChange)
+		}
+		if !rbs[r.Branch] {
+			rbs[r.Branch] = true
+			continue
+		}
+		// only the first rule with a branch pattern can ever match
+		msg := fmt.Sprintf("duplicate rule branch pattern %s", r.Branch)
+		if o.Strict {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		l.Warn(msg)
+	}
 	a := &Analyzer{
-		git:    o.Git,
-		logger: l,
-		parser: o.Parser,
-		rules:  o.Rules,
+		buildOnUnchanged:   o.BuildOnUnchanged,
+		excludeAuthors:     eas,
+		excludeTrailers:    o.ExcludeTrailers,
+		git:                o.Git,
+		includeTags:        its,
+		logger:             l,
+		now:                n,
+		parser:             o.Parser,
+		pathFilter:         pf,
+		prereleaseStart:    ps,
+		rules:              o.Rules,
+		scheme:             s,
+		strict:             o.Strict,
+		tagPrefix:          tp,
+		tagRemote:          o.TagRemote,
+		transform:          o.Transform,
+		useNotes:           o.UseNotes,
+		zeroMajorSemantics: o.ZeroMajorSemantics,
 	}
 	return a, nil
 }
 
-// Parses a list of tags into [Version] structs, sorts them and returns them.
-// Tags that aren't prefixed with 'v' (e.g, v1.0.0) are discarded.
-// Once stripped of the 'v' prefix, tags that aren't version parseable are discarded.
-func (a Analyzer) getSortedVersionsFromTags(ts []string) []Version {
-	vs := []Version{}
+// Compiles a glob into an anchored regex.
+// Supports '**' (any path), '*' (any path segment characters) and '?' (any single path segment character).
+func compileGlob(g string) (*regexp.Regexp, error) {
+	s := "^"
+	for i := 0; i < len(g); i++ {
+		switch {
+		case strings.HasPrefix(g[i:], "**/"):
+			s += "(?:.*/)?"
+			i += 2
+		case strings.HasPrefix(g[i:], "**"):
+			s += ".*"
+			i += 1
+		case g[i] == '*':
+			s += "[^/]*"
+		case g[i] == '?':
+			s += "[^/]"
+		default:
+			s += regexp.QuoteMeta(string(g[i]))
+		}
+	}
+	s += "$"
+	return regexp.Compile(s)
+}
+
+// Returns true if the commit with the provided hash touches a path matching the path filter.
+// If no path filter is configured, all commits match.
+func (a Analyzer) matchesPathFilter(h string) (bool, error) {
+	if len(a.pathFilter) == 0 {
+		return true, nil
+	}
+	fs, err := a.git.GetChangedFiles(h)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range fs {
+		for _, pre := range a.pathFilter {
+			if pre.MatchString(f) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Returns true if the commit should be ignored - either because its author matches an
+// exclude author pattern or because it carries an exclude trailer.
+func (a Analyzer) isCommitExcluded(c GitCommit) bool {
+	au := fmt.Sprintf("%s <%s>", c.AuthorName, c.AuthorEmail)
+	for _, eare := range a.excludeAuthors {
+		if eare.MatchString(au) {
+			return true
+		}
+	}
+	for _, l := range strings.Split(c.Message, "\n") {
+		l = strings.TrimSpace(l)
+		for _, et := range a.excludeTrailers {
+			if strings.HasPrefix(l, et) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parses a commit to determine its [VersionChange].
+// If notes are used and the commit has a note, the note is treated as additional message text - either the message or the note header can classify the commit.
+// Notes are only read when used.
+func (a Analyzer) parseCommit(c GitCommit) (VersionChange, error) {
+	if !a.useNotes {
+		return a.parser.Parse(c.Message), nil
+	}
+	n, err := a.git.GetNote(c.Hash)
+	if err != nil {
+		return VersionChange{}, err
+	}
+	n = strings.TrimSpace(n)
+	if n == "" {
+		return a.parser.Parse(c.Message), nil
+	}
+	m := strings.TrimSpace(c.Message)
+	vc := a.parser.Parse(fmt.Sprintf("%s\n\n%s", m, n))
+	nvc := a.parser.Parse(fmt.Sprintf("%s\n\n%s", n, m))
+	if vc.Compare(nvc) < 0 {
+		vc = nvc
+	}
+	return vc, nil
+}
+
+// Returns true if the tag matches at least one include tag pattern.
+// If no include tag patterns are configured, all tags are included.
+func (a Analyzer) isTagIncluded(t string) bool {
+	if len(a.includeTags) == 0 {
+		return true
+	}
+	for _, itre := range a.includeTags {
+		if itre.MatchString(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// A TaggedVersion pairs a git tag with the [Version] parsed from it
+type TaggedVersion struct {
+	Tag     string
+	Version Version
+}
+
+// Parses a list of tags into [TaggedVersion] structs, sorts them (descending) and returns them.
+// Tags that don't match an include tag pattern (if configured) are discarded.
+// Tags that aren't prefixed with the tag prefix (e.g, 'v' for v1.0.0) are discarded.
+// Once stripped of the tag prefix, tags that aren't version parseable are discarded.
+func (a Analyzer) getSortedTaggedVersions(ts []string) []TaggedVersion {
+	tvs := []TaggedVersion{}
 	for _, t := range ts {
-		if !strings.HasPrefix(t, "v") {
-			// ignore tags without 'v' prefix
+		if !a.isTagIncluded(t) {
+			// ignore tags not matching include patterns
+			continue
+		}
+		if !strings.HasPrefix(t, a.tagPrefix) {
+			// ignore tags without tag prefix
 			continue
 		}
-		// remove 'v' prefix
-		t = t[1:]
-		//collect parseable versions
-		v, err := NewVersion(t)
+		//collect parseable versions (with tag prefix removed)
+		v, err := NewVersion(t[len(a.tagPrefix):])
 		if err != nil {
 			continue
 		}
-		vs = append(vs, v)
+		tvs = append(tvs, TaggedVersion{Tag: t, Version: v})
 	}
 	// sort and reverse collected versions
-	slices.SortFunc(vs, func(a Version, b Version) int {
-		return a.Compare(b)
+	// (metadata breaks ties - keeping the selection deterministic)
+	slices.SortFunc(tvs, func(a TaggedVersion, b TaggedVersion) int {
+		return a.Version.CompareStrict(b.Version)
 	})
-	slices.Reverse(vs)
+	slices.Reverse(tvs)
+	return tvs
+}
+
+// Parses a list of tags into [Version] structs, sorts them (descending) and returns them.
+// See [Analyzer.getSortedTaggedVersions].
+func (a Analyzer) getSortedVersionsFromTags(ts []string) []Version {
+	vs := []Version{}
+	for _, tv := range a.getSortedTaggedVersions(ts) {
+		vs = append(vs, tv.Version)
+	}
 	return vs
 }
 
+// Lists the tags used to determine repo-wide versions.
+// Tags are sourced from the tag remote (if configured) - otherwise, from the local repository.
+func (a Analyzer) listTags() ([]string, error) {
+	if a.tagRemote != "" {
+		return a.git.ListRemoteTags(a.tagRemote)
+	}
+	return a.git.ListTags()
+}
+
+// Lists every version tag in the local repository (or the tag remote, see [AnalyzerOpts.TagRemote]) alongside its parsed [Version].
+// Results are sorted in descending order - the first result is the current version.
+func (a Analyzer) ListVersions() ([]TaggedVersion, error) {
+	ts, err := a.listTags()
+	if err != nil {
+		return []TaggedVersion{}, err
+	}
+	return a.getSortedTaggedVersions(ts), nil
+}
+
 // Represents repo-wide information used to inform version bump behavior
 type repoData struct {
+	Release Version // Highest non-prerelease version in entire repository
 	Version Version // Highest version in entire repositroy
 }
 
 // Analyzes local repository and returns a [repoData].
-func (a Analyzer) getRepoData() (repoData, error) {
+// If non-nil, only versions accepted by the filter are considered.
+func (a Analyzer) getRepoData(f func(Version) bool) (repoData, error) {
+	r := Version{}
 	v := Version{}
-	ts, err := a.git.ListTags()
+	ts, err := a.listTags()
 	if err != nil {
 		return repoData{}, err
 	}
-	vs := a.getSortedVersionsFromTags(ts)
+	vs := []Version{}
+	for _, cv := range a.getSortedVersionsFromTags(ts) {
+		if f == nil || f(cv) {
+			vs = append(vs, cv)
+		}
+	}
 	if len(vs) > 0 {
 		v = vs[0]
 	}
-	return repoData{Version: v}, nil
+	for _, cv := range vs {
+		if cv.Prerelease == (Prerelease{}) {
+			r = cv
+			break
+		}
+	}
+	return repoData{Release: r, Version: v}, nil
 }
 
 // Obtains commit ancestor information used to inform version bump behavior
 type ancestorData struct {
+	Hash          string        // The hash of the commit holding the highest non-prerelease version in the commit ancestry
+	Matched       int           // The number of scanned commits that resulted in a version change
+	Scanned       int           // The number of commits between the head and the highest non-prerelease version in the commit ancestry
 	Version       Version       // The highest non-prerelease version in the commit ancestry
 	VersionChange VersionChange // The largest change between the head and the highest non-prerelease version in the commit ancestry
 }
 
-// Analyzes a commit's ancestry (starting from HEAD) and creates an [ancestorData].
-func (a Analyzer) getAncestorData() (ancestorData, error) {
+// Gets the [VersionChange] implied by a single commit.
+// Excluded commits (see [Analyzer.isCommitExcluded]) and commits outside the path filter imply no change.
+func (a Analyzer) getCommitChange(c GitCommit) (VersionChange, error) {
+	none := VersionChange{Value: "none"}
+	if a.isCommitExcluded(c) {
+		a.logger.Debug(fmt.Sprintf("commit: %s (skipped: excluded)", c.Hash))
+		return none, nil
+	}
+	m, err := a.matchesPathFilter(c.Hash)
+	if err != nil {
+		return VersionChange{}, err
+	}
+	if !m {
+		a.logger.Debug(fmt.Sprintf("commit: %s (skipped: path filter)", c.Hash))
+		return none, nil
+	}
+	cvc, err := a.parseCommit(c)
+	if err != nil {
+		return VersionChange{}, err
+	}
+	if cvc.Value == "none" {
+		a.logger.Debug(fmt.Sprintf("commit: %s (unmatched: %s)", c.Hash, strings.Split(c.Message, "\n")[0]))
+		return cvc, nil
+	}
+	a.logger.Debug(fmt.Sprintf("commit: %s (change: %s)", c.Hash, cvc.Value))
+	return cvc, nil
+}
+
+// Analyzes a commit's ancestry (starting from the provided head - or HEAD if empty) and creates an [ancestorData].
+func (a Analyzer) getAncestorData(head string) (ancestorData, error) {
+	h := ""
+	ma := 0
+	sc := 0
 	v := Version{}
 	vc := VersionChange{Value: "none"}
 
-	err := a.git.IterCommits("", func(c GitCommit) error {
+	err := a.git.IterCommits(head, func(c GitCommit) error {
 		// collect *only* release versions attached to current commit
 		cvs := []Version{}
 		for _, cv := range a.getSortedVersionsFromTags(c.Tags) {
@@ -142,8 +449,15 @@ func (a Analyzer) getAncestorData() (ancestorData, error) {
 
 		// only process commit if commit not part of release
 		if len(cvs) == 0 {
-			cvc := a.parser.Parse(c.Message)
-			a.logger.Debug(fmt.Sprintf("commit: %s (change: %s)", c.Hash, cvc.Value))
+			sc += 1
+			cvc, err := a.getCommitChange(c)
+			if err != nil {
+				return err
+			}
+			if cvc.Value == "none" {
+				return nil
+			}
+			ma += 1
 			if vc.Compare(cvc) < 0 {
 				vc = cvc
 			}
@@ -151,14 +465,85 @@ func (a Analyzer) getAncestorData() (ancestorData, error) {
 		}
 
 		// stop iteration - commit part of release
+		h = c.Hash
 		v = cvs[0]
 		a.logger.Debug(fmt.Sprintf("commit: %s (release: %s)", c.Hash, v.String("")))
 		return &StopIter{}
 	})
 	if err != nil {
-		return ancestorData{}, nil
+		return ancestorData{}, err
+	}
+	if h == "" {
+		// no release found - history may have been truncated by a shallow clone
+		s, err := a.git.IsShallow()
+		if err != nil {
+			return ancestorData{}, err
+		}
+		if s {
+			msg := "shallow repository - commit history may be truncated (fetch full history with 'git fetch --unshallow')"
+			if a.strict {
+				return ancestorData{}, fmt.Errorf("%s", msg)
+			}
+			a.logger.Warn(msg)
+		}
+	}
+	return ancestorData{Hash: h, Matched: ma, Scanned: sc, Version: v, VersionChange: vc}, nil
+}
+
+// Gets the aggregate [VersionChange] implied by the commits reachable from 'to' but not from 'from'.
+// Accepts any git revision (e.g., tags, branches, hashes).
+// Returns an error if 'from' is not an ancestor of 'to'.
+func (a Analyzer) ChangeBetween(from string, to string) (VersionChange, error) {
+	a.logger.Info(fmt.Sprintf("comparing %s..%s", from, to))
+	vc := VersionChange{Value: "none"}
+	f, err := a.iterUniqueCommits(from, to, func(c GitCommit) error {
+		cvc, err := a.getCommitChange(c)
+		if err != nil {
+			return err
+		}
+		if vc.Compare(cvc) < 0 {
+			vc = cvc
+		}
+		return nil
+	})
+	if err != nil {
+		return VersionChange{}, err
+	}
+	if !f {
+		return VersionChange{}, fmt.Errorf("%s is not an ancestor of %s", from, to)
+	}
+	return vc, nil
+}
+
+// Iterates through the commits reachable from 'to' but not from 'from'.
+// Returns true if 'from' is an ancestor of 'to'.
+func (a Analyzer) iterUniqueCommits(from string, to string, cb func(c GitCommit) error) (bool, error) {
+	// collect commits reachable from 'from'
+	fh := ""
+	fhs := map[string]bool{}
+	err := a.git.IterCommits(from, func(c GitCommit) error {
+		if fh == "" {
+			fh = c.Hash
+		}
+		fhs[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	f := false
+	err = a.git.IterCommits(to, func(c GitCommit) error {
+		if fhs[c.Hash] {
+			f = f || c.Hash == fh
+			return nil
+		}
+		return cb(c)
+	})
+	if err != nil {
+		return false, err
 	}
-	return ancestorData{Version: v, VersionChange: vc}, nil
+	return f, nil
 }
 
 // Matches a branch name to a [Rule].
@@ -177,99 +562,526 @@ func (a Analyzer) findRule(bn string) (RuleMatch, error) {
 	return RuleMatch{}, fmt.Errorf("no rule found for %s", bn)
 }
 
+// A CheckResult is the outcome of a single check performed by [Analyzer.Check]
+type CheckResult struct {
+	Name    string
+	OK      bool
+	Message string
+}
+
+// Checks whether the local repository is usable with the analyzer's configuration.
+// Checks are read-only - failed checks are reported rather than returned as errors.
+func (a Analyzer) Check() []CheckResult {
+	crs := []CheckResult{}
+
+	h, err := a.git.GetHeadHash()
+	if err != nil {
+		crs = append(crs, CheckResult{Name: "commits", Message: fmt.Sprintf("head has no commits (%s)", err.Error())})
+	} else {
+		crs = append(crs, CheckResult{Name: "commits", OK: true, Message: fmt.Sprintf("head is %s", h[:7])})
+	}
+
+	b, err := a.git.GetCurrentBranch()
+	if err != nil {
+		crs = append(crs, CheckResult{Name: "rule", Message: fmt.Sprintf("current branch unknown (%s)", err.Error())})
+	} else if rm, err := a.findRule(b); err != nil {
+		crs = append(crs, CheckResult{Name: "rule", Message: err.Error()})
+	} else {
+		crs = append(crs, CheckResult{Name: "rule", OK: true, Message: fmt.Sprintf("branch %s matches rule %s", b, rm.Rule.Branch)})
+	}
+
+	ts, err := a.listTags()
+	if err != nil {
+		crs = append(crs, CheckResult{Name: "tags", Message: err.Error()})
+	} else if tvs := a.getSortedTaggedVersions(ts); len(tvs) == 0 {
+		crs = append(crs, CheckResult{Name: "tags", OK: true, Message: fmt.Sprintf("no version tags (of %d tag(s)) - versions start from 0.0.0", len(ts))})
+	} else {
+		crs = append(crs, CheckResult{Name: "tags", OK: true, Message: fmt.Sprintf("%d of %d tag(s) are versions (current: %s)", len(tvs), len(ts), tvs[0].Version.String(""))})
+	}
+
+	return crs
+}
+
+// Returns a string representation of [Version] (see [Version.String]).
+// The 'git' format uses the analyzer's tag prefix (see [AnalyzerOpts.TagPrefix]).
+func (a Analyzer) FormatVersion(v Version, f string) string {
+	if NormalizeFormat(f) == "git" {
+		return v.Tag(a.tagPrefix)
+	}
+	return v.String(f)
+}
+
 // Gets the current [Version] for the local repository.
 func (a Analyzer) GetCurrentVersion() (Version, error) {
-	rd, err := a.getRepoData()
+	rd, err := a.getRepoData(nil)
 	if err != nil {
 		return Version{}, err
 	}
 	return rd.Version, nil
 }
 
+// Gets the release [Version] that the current (prerelease) version promotes to (e.g., 1.2.0-rc.3 -> 1.2.0).
+// Returns an error if the current version is not a prerelease.
+func (a Analyzer) GetPromotedVersion() (Version, error) {
+	cv, err := a.GetCurrentVersion()
+	if err != nil {
+		return Version{}, err
+	}
+	if cv.Prerelease == (Prerelease{}) {
+		return Version{}, fmt.Errorf("current version %s is not a prerelease", cv.String(""))
+	}
+	a.logger.Info(fmt.Sprintf("promoting version: %s", cv.String("")))
+	return cv.Release(), nil
+}
+
+// Gets the most recent release [Version] reachable from the current head, alongside the hash of its commit.
+// Unlike [Analyzer.GetCurrentVersion], prereleases and tags not reachable from the current head are ignored.
+// Returns a zero-value [Version] and an empty hash if no release is found.
+func (a Analyzer) GetLastRelease() (Version, string, error) {
+	ad, err := a.getAncestorData("")
+	if err != nil {
+		return Version{}, "", err
+	}
+	return ad.Version, ad.Hash, nil
+}
+
+// Validates a user-supplied version as the next version, bypassing commit analysis.
+// Returns an error if the version is invalid.
+// Returns an error if the version is not greater than the current version (unless force is true).
+func (a Analyzer) OverrideNextVersion(v string, force bool) (Version, error) {
+	nv, err := NewVersion(v)
+	if err != nil {
+		return Version{}, err
+	}
+	cv, err := a.GetCurrentVersion()
+	if err != nil {
+		return Version{}, err
+	}
+	if nv.Compare(cv) <= 0 {
+		if !force {
+			return Version{}, fmt.Errorf("version %s is not greater than current version %s", nv.String(""), cv.String(""))
+		}
+		a.logger.Warn(fmt.Sprintf("forcing version %s (current version: %s)", nv.String(""), cv.String("")))
+	}
+	return nv, nil
+}
+
+// Verifies that the current head is signed by a key within an armored keyring (see [Git.VerifyCommitSignature]).
+// Returns an error if the current head is unsigned or if its signature cannot be verified by the keyring.
+func (a Analyzer) VerifyHeadSignature(keyring string) error {
+	h, err := a.git.GetHash("HEAD")
+	if err != nil {
+		return err
+	}
+	ok, err := a.git.VerifyCommitSignature(h, keyring)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("commit %s is not signed by a trusted key", h)
+	}
+	return nil
+}
+
+// Determines whether the current head is already tagged with a release.
+// Returns the highest release [Version] attached to the current head if so.
+func (a Analyzer) IsReleased() (bool, Version, error) {
+	vs := []Version{}
+	err := a.git.IterCommits("", func(c GitCommit) error {
+		for _, v := range a.getSortedVersionsFromTags(c.Tags) {
+			if v.Prerelease != (Prerelease{}) {
+				continue
+			}
+			vs = append(vs, v)
+		}
+		// only the current head is inspected
+		return &StopIter{}
+	})
+	if err != nil {
+		return false, Version{}, err
+	}
+	if len(vs) == 0 {
+		return false, Version{}, nil
+	}
+	return true, vs[0], nil
+}
+
 // Used to replace invalid characters in prerelease tokens or metadata
 // with valid characters (probably a '-').
 var nonAlphaNumericRegex = regexp.MustCompile("[^a-zA-Z0-9]+")
 
+// Replaces characters that are invalid within prerelease tokens or metadata with '-'.
+func SanitizeIdentifier(s string) string {
+	return nonAlphaNumericRegex.ReplaceAllString(s, "-")
+}
+
+// Replaces characters that are invalid within metadata with '-' - preserving '.' separators between identifiers (e.g., 'build.5').
+// Empty identifiers (e.g., 'a..b') are dropped.
+func SanitizeMetadata(s string) string {
+	ids := []string{}
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			continue
+		}
+		ids = append(ids, SanitizeIdentifier(id))
+	}
+	return strings.Join(ids, ".")
+}
+
+// Returned when no commits imply a version change (see [Analyzer.GetNextVersion])
+var ErrVersionUnchanged = errors.New("version unchanged")
+
 // Gets the next [Version] for the local repository.
+// Returns [ErrVersionUnchanged] if no commits imply a version change.
 func (a Analyzer) GetNextVersion() (Version, error) {
 	b, err := a.git.GetCurrentBranch()
 	if err != nil {
 		return Version{}, err
 	}
+	v, _, err := a.getNextVersion(b, "")
+	return v, err
+}
+
+// Gets the next [Version] for the local repository (see [Analyzer.GetNextVersion]) - and whether the version changed.
+// Versions built from the last release (see [AnalyzerOpts.BuildOnUnchanged]) are unchanged.
+func (a Analyzer) GetNextVersionChanged() (Version, bool, error) {
+	b, err := a.git.GetCurrentBranch()
+	if err != nil {
+		return Version{}, false, err
+	}
+	return a.getNextVersion(b, "")
+}
+
+// Gets the next [Version] as if the provided branch were merged into the target branch (e.g., to preview a pull request).
+// The target branch's rule is used.  Commits reachable from the branch (but not from the target) are analyzed
+// alongside the target branch's own history - no merge is performed.
+func (a Analyzer) GetMergePreviewVersion(target string, branch string) (Version, error) {
+	v, _, err := a.getNextVersion(target, branch)
+	return v, err
+}
+
+// Gets the merge preview [Version] (see [Analyzer.GetMergePreviewVersion]) - and whether the version changed (see [Analyzer.GetNextVersionChanged]).
+func (a Analyzer) GetMergePreviewVersionChanged(target string, branch string) (Version, bool, error) {
+	return a.getNextVersion(target, branch)
+}
+
+// Gets the next [Version] for a branch.
+// When a merge branch is provided, the version is computed as if the merge branch were merged into the branch.
+// Also returns whether the version changed (i.e., false when built from the last release - see [AnalyzerOpts.BuildOnUnchanged]).
+func (a Analyzer) getNextVersion(b string, mb string) (Version, bool, error) {
 	a.logger.Info(fmt.Sprintf("branch: %s", b))
 	rm, err := a.findRule(b)
 	r := rm.Rule
 	if err != nil {
-		return Version{}, err
+		return Version{}, false, err
 	}
 	a.logger.Info(fmt.Sprintf("rule: %s", r.Branch))
-	rd, err := a.getRepoData()
+	hd := ""
+	if mb != "" {
+		// analyze the target branch's history (rather than HEAD)
+		hd = b
+	}
+	ad, err := a.getAncestorData(hd)
 	if err != nil {
-		return Version{}, err
+		return Version{}, false, err
+	}
+	if mb != "" {
+		// include commits unique to the merge branch
+		a.logger.Info(fmt.Sprintf("merge preview: %s into %s", mb, b))
+		_, err := a.iterUniqueCommits(b, mb, func(c GitCommit) error {
+			ad.Scanned += 1
+			cvc, err := a.getCommitChange(c)
+			if err != nil {
+				return err
+			}
+			if cvc.Value == "none" {
+				return nil
+			}
+			ad.Matched += 1
+			if ad.VersionChange.Compare(cvc) < 0 {
+				ad.VersionChange = cvc
+			}
+			return nil
+		})
+		if err != nil {
+			return Version{}, false, err
+		}
+	}
+	vc, c := clampChange(ad.VersionChange, r.MaxChange)
+	if c {
+		// change too large for rule (e.g., a minor change on a hotfix branch)
+		msg := fmt.Sprintf("change %s exceeds max change %s for rule %s", ad.VersionChange.Value, r.MaxChange, r.Branch)
+		if a.strict {
+			return Version{}, false, fmt.Errorf("%s", msg)
+		}
+		a.logger.Warn(fmt.Sprintf("%s - clamping", msg))
+		ad.VersionChange = vc
+	}
+	if ad.VersionChange.Value == "none" {
+		if a.buildOnUnchanged && r.PrereleaseToken == "" {
+			// release rule - build the ancestor version
+			hr := "HEAD"
+			if mb != "" {
+				hr = mb
+			}
+			h, err := a.git.GetHash(hr)
+			if err != nil {
+				return Version{}, false, err
+			}
+			version := ad.Version
+			version.Metadata = h[:7]
+			a.logger.Info(fmt.Sprintf("version unchanged - building version: %s", version.String("")))
+			v, err := a.applyTransform(version)
+			return v, false, err
+		}
+		if ad.Scanned == 0 {
+			a.logger.Warn("no commits since last release")
+		} else {
+			a.logger.Warn(fmt.Sprintf("%d commit(s) since last release - none matched a version bump tag", ad.Scanned))
+		}
+		return Version{}, false, ErrVersionUnchanged
+	}
+	a.logger.Info(fmt.Sprintf("ancestor version: %s", ad.Version.String("")))
+	a.logger.Info(fmt.Sprintf("ancestor change: %s (%d of %d commit(s) matched)", ad.VersionChange.Value, ad.Matched, ad.Scanned))
+	var f func(Version) bool
+	if r.MaxChange != "" {
+		// only consider the ancestor's release line (e.g., 1.2.x on a hotfix branch)
+		// so that newer releases on other branches don't leak into the next version
+		f = func(v Version) bool {
+			return inReleaseLine(v, ad.Version, r.MaxChange)
+		}
+	}
+	rd, err := a.getRepoData(f)
+	if err != nil {
+		return Version{}, false, err
 	}
-
 	a.logger.Info(fmt.Sprintf("repo version: %s", rd.Version.String("")))
-	ad, err := a.getAncestorData()
+
+	var version Version
+	switch a.scheme {
+	case "calver":
+		version = a.getNextCalverVersion(rd, r.PrereleaseToken != "")
+	default:
+		version = a.getNextSemverVersion(rd, ad, r.PrereleaseToken != "")
+	}
+	mv, err := parseMinVersion(r)
+	if err != nil {
+		return Version{}, false, err
+	}
+	if version.Release().Compare(mv) < 0 {
+		// raise version to floor if below floor
+		a.logger.Info(fmt.Sprintf("version %s below min version %s", version.String(""), mv.String("")))
+		version = mv
+	}
+	version = applyRule(version, r, rm.Data, &a.prereleaseStart)
+	v, err := a.applyTransform(version)
+	return v, true, err
+}
+
+// Post-processes the next version with the configured transform (if any).
+func (a Analyzer) applyTransform(v Version) (Version, error) {
+	if a.transform == nil {
+		return v, nil
+	}
+	// (after rule metadata, allowing the transform to override it)
+	v, err := a.transform(v)
 	if err != nil {
 		return Version{}, err
 	}
-	if ad.VersionChange.Value == "none" {
-		return Version{}, fmt.Errorf("version unchanged")
+	a.logger.Info(fmt.Sprintf("transformed version: %s", v.String("")))
+	return v, nil
+}
+
+// Computes the next semantic [Version] (excluding the prerelease bump) from repo and ancestor data.
+// The ancestor <-> repo version diff is compared to the largest change to avoid bumping a version
+// that was already bumped by a prior prerelease.
+func (a Analyzer) getNextSemverVersion(rd repoData, ad ancestorData, prerelease bool) Version {
+	if a.zeroMajorSemantics && rd.Version.Major == 0 {
+		// 0.x versions are unstable - downgrade the change by one level
+		switch ad.VersionChange.Value {
+		case "major":
+			ad.VersionChange = VersionChange{Value: "minor"}
+		case "minor":
+			ad.VersionChange = VersionChange{Value: "patch"}
+		}
+		a.logger.Info(fmt.Sprintf("zero major change: %s", ad.VersionChange.Value))
 	}
-	a.logger.Info(fmt.Sprintf("ancestor version: %s", ad.Version.String("")))
-	a.logger.Info(fmt.Sprintf("ancestor change: %s", ad.VersionChange.Value))
 
 	d := ad.Version.Diff(rd.Version)
 	a.logger.Info(fmt.Sprintf("repo + ancestor version diff: %s", d.Value))
 
-	var version Version
-	if r.PrereleaseToken != "" {
+	v := bumpSemverVersion(rd.Version, d, ad.VersionChange, prerelease)
+
+	if v.Release().Compare(rd.Release) <= 0 {
+		// version is already released (e.g., branch diverged before the latest release)
+		// bump past the latest release
+		v = rd.Release.Bump(ad.VersionChange)
+		a.logger.Info(fmt.Sprintf("version collides with release %s - bumping to %s", rd.Release.String(""), v.String("")))
+	}
+	return v
+}
+
+// Caps a [VersionChange] at a maximum change value (e.g., 'patch') - an empty maximum imposes no cap.
+// Returns true if the change was clamped.
+func clampChange(c VersionChange, max string) (VersionChange, bool) {
+	if max == "" || c.Compare(VersionChange{Value: max}) <= 0 {
+		return c, false
+	}
+	c.Value = max
+	return c, true
+}
+
+// Determines whether a [Version] belongs to the release line of a base version for a max change.
+// For example, 1.2.5 belongs to the patch release line of 1.2.3 - 1.3.0 does not.
+func inReleaseLine(v Version, b Version, max string) bool {
+	switch max {
+	case "patch":
+		return v.Major == b.Major && v.Minor == b.Minor
+	case "minor":
+		return v.Major == b.Major
+	}
+	return true
+}
+
+// Bumps a semantic [Version] (excluding the prerelease bump) by a change.
+// The diff describes the change already applied to the version (relative to the last release) -
+// the version is not bumped again if the diff is at least as large as the change.
+func bumpSemverVersion(v Version, d VersionChange, c VersionChange, prerelease bool) Version {
+	if prerelease {
 		// rule is prerelease
-		if d.Compare(ad.VersionChange) < 0 {
-			// ancestor <-> repo diff is less than largest change
+		if d.Compare(c) < 0 {
+			// diff is less than change
 			// bump version
-			version = rd.Version.Bump(ad.VersionChange)
-		} else {
-			// ancestor <-> repo diff is bigger than largest change
-			// no bump needed
-			version = rd.Version
+			return v.Bump(c)
 		}
+		// diff is bigger than change
+		// no bump needed
+		return v
+	}
+	// rule is not prerelease
+	if v.Prerelease == (Prerelease{}) {
+		// version is not prerelease
+		// bump version
+		return v.Bump(c)
+	}
+	// version is prerelease
+	if d.Compare(c) < 0 {
+		// diff less than change
+		// bump version
+		return v.Bump(c)
+	}
+	// diff bigger than change
+	// only strip prerelease data
+	return v.Release()
+}
+
+// Applies a [Rule] to a [Version] - bumping the prerelease (if the rule has a prerelease token) and setting metadata (if the rule has metadata).
+// Template fields within the rule are replaced with the provided data (see [injectData]).
+func applyRule(v Version, r Rule, d map[string]string, ps *int) Version {
+	if r.PrereleaseToken != "" {
 		// bump prerelease version
-		pt := a.injectData(rm.Data, r.PrereleaseToken)
-		pt = nonAlphaNumericRegex.ReplaceAllString(pt, "-")
-		version = version.Bump(VersionChange{Value: "prerelease", PrereleaseToken: pt})
-	} else {
-		// rule is not prerelease
-		if rd.Version.Prerelease == (Prerelease{}) {
-			// repo version is not prerelease
-			// bump version
-			version = rd.Version.Bump(ad.VersionChange)
-		} else {
-			// repo version is prerelease
-			if d.Compare(ad.VersionChange) < 0 {
-				// ancestor <-> repo diff bigger than largest change
-				// bump version
-				version = rd.Version.Bump(ad.VersionChange)
-			} else {
-				// ancestor <-> repo diff less than largest change
-				// only strip prerelease data
-				version = rd.Version.Release()
-			}
-		}
+		pt := injectData(d, r.PrereleaseToken)
+		pt = SanitizeIdentifier(pt)
+		v = v.Bump(VersionChange{Value: "prerelease", PrereleaseToken: pt, PrereleaseStart: ps})
 	}
 	if r.Metadata != "" {
 		// add metadata if configured
-		md := a.injectData(rm.Data, r.Metadata)
-		md = nonAlphaNumericRegex.ReplaceAllString(md, "-")
-		version.Metadata = md
+		md := injectData(d, r.Metadata)
+		md = SanitizeMetadata(md)
+		v.Metadata = md
+	}
+	return v
+}
+
+// Computes the next semantic [Version] from in-memory inputs (i.e., without a git repository).
+// Expects the current version to be the latest version and the messages to be those of the commits since the latest release.
+// If the current version is a prerelease, the change since the latest release is inferred from its components (e.g., 1.1.0-rc.1 implies a 'minor' change).
+// Capture groups are not available - rule template fields are used verbatim.
+// Changes larger than the rule's max change (if any) are clamped.
+// Versions below the rule's min version (if any) are raised to it.
+// Returns an error if no message results in a version change.
+func ComputeNextVersion(current Version, messages []string, rule Rule, p Parser) (Version, error) {
+	c := VersionChange{Value: "none"}
+	for _, m := range messages {
+		mc := p.Parse(m)
+		if c.Compare(mc) < 0 {
+			c = mc
+		}
+	}
+	if c.Value == "none" {
+		return Version{}, ErrVersionUnchanged
+	}
+	c, _ = clampChange(c, rule.MaxChange)
+	d := VersionChange{Value: "none"}
+	if current.Prerelease != (Prerelease{}) {
+		switch {
+		case current.Patch != 0:
+			d = VersionChange{Value: "patch"}
+		case current.Minor != 0:
+			d = VersionChange{Value: "minor"}
+		default:
+			d = VersionChange{Value: "major"}
+		}
+	}
+	v := bumpSemverVersion(current, d, c, rule.PrereleaseToken != "")
+	mv, err := parseMinVersion(rule)
+	if err != nil {
+		return Version{}, err
+	}
+	if v.Release().Compare(mv) < 0 {
+		v = mv
+	}
+	return applyRule(v, rule, map[string]string{}, nil), nil
+}
+
+// Parses the min version of a [Rule].
+// Returns a zero-value [Version] if the rule has no min version.
+// Returns an error if the min version is invalid or is not a release (e.g., '2.0.0-rc.1').
+func parseMinVersion(r Rule) (Version, error) {
+	if r.MinVersion == "" {
+		return Version{}, nil
+	}
+	mv, err := NewVersion(r.MinVersion)
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid min version for rule %s: %w", r.Branch, err)
+	}
+	if mv != mv.Release() {
+		return Version{}, fmt.Errorf("invalid min version for rule %s: %s is not a release", r.Branch, r.MinVersion)
+	}
+	return mv, nil
+}
+
+// Computes the next calendar [Version] (excluding the prerelease bump) from repo data.
+// Calendar versions take the form YYYY.MM.PATCH, where the date is derived from the current time.
+// The patch component increments within the same month and resets when the month rolls over.
+func (a Analyzer) getNextCalverVersion(rd repoData, prerelease bool) Version {
+	n := a.now()
+	cv := Version{Major: n.Year(), Minor: int(n.Month())}
+	a.logger.Info(fmt.Sprintf("calendar version: %s", cv.String("")))
+	if rd.Version.Major != cv.Major || rd.Version.Minor != cv.Minor {
+		// month rolled over
+		// reset patch
+		return cv
+	}
+	if rd.Version.Prerelease == (Prerelease{}) {
+		// repo version is not prerelease
+		// bump patch
+		return rd.Version.Bump(VersionChange{Value: "patch"})
+	}
+	if prerelease {
+		// repo version is prerelease, rule is prerelease
+		// no bump needed
+		return rd.Version
 	}
-	return version, nil
+	// repo version is prerelease, rule is not prerelease
+	// only strip prerelease data
+	return rd.Version.Release()
 }
 
 // Given a map of values, replace template fields in string
 // (format: '{<key>}') with respective map values.
 // Returns a string with values replaced
-func (a Analyzer) injectData(d map[string]string, v string) string {
+func injectData(d map[string]string, v string) string {
 	for key, value := range d {
 		s := fmt.Sprintf("{%s}", key)
 		v = strings.ReplaceAll(v, s, value)
@@ -1,8 +1,12 @@
 package versionctl
 
 import (
+	"bytes"
+	"fmt"
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -12,7 +16,9 @@ type AnalyzerTestData struct {
 	Repo     *TestRepo
 }
 
-func createAnalyzerTestData(t testing.TB) *AnalyzerTestData {
+// Helper method to create an analyzer backed by a fresh git repository.
+// Optional callbacks can modify the [AnalyzerOpts] before the analyzer is constructed.
+func createAnalyzerTestData(t testing.TB, fs ...func(o *AnalyzerOpts)) *AnalyzerTestData {
 	t.Helper()
 	require := require.New(t)
 	wd, err := os.Getwd()
@@ -37,7 +43,7 @@ func createAnalyzerTestData(t testing.TB) *AnalyzerTestData {
 		},
 	})
 	require.Nil(err)
-	a, err := NewAnalyzer(&AnalyzerOpts{
+	o := &AnalyzerOpts{
 		Git:    g,
 		Parser: p,
 		Rules: []Rule{
@@ -54,7 +60,11 @@ func createAnalyzerTestData(t testing.TB) *AnalyzerTestData {
 				Metadata:        "{branch}",
 			},
 		},
-	})
+	}
+	for _, f := range fs {
+		f(o)
+	}
+	a, err := NewAnalyzer(o)
 	require.Nil(err)
 	return &AnalyzerTestData{
 		Analyzer: a,
@@ -85,160 +95,1685 @@ func TestAnalyzerGetCurrentVersion(t *testing.T) {
 		require.Nil(err)
 		require.Equal(Version{Major: 1}, v)
 	})
-}
 
-func TestAnalyzerGetNextVersion(t *testing.T) {
-	t.Run("prerelease branch, repo version diff < change", func(t *testing.T) {
+	t.Run("selects deterministically among metadata-differing tags", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.2.3+build.2")
+		td.Repo.createGitTag("v1.2.3+build.10")
+		td.Repo.createGitTag("v1.2.3+build.1")
+		td.Repo.createGitTag("v1.2.3")
+
+		for i := 0; i < 10; i++ {
+			v, err := td.Analyzer.GetCurrentVersion()
+
+			require.Nil(err)
+			require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Metadata: "build.10"}, v)
+		}
+	})
+
+	t.Run("ignores tags with surrounding junk", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		// branch = prerelease
-		td.Repo.checkoutGitBranch("dev")
-		// repo version diff = patch (0.0.2 <-> 0.0.1)
-		td.Repo.createGitTag("v0.0.2")
-		td.Repo.createGitCommit("next")
 		td.Repo.createGitTag("v0.0.1")
-		// change = major
-		td.Repo.createGitCommit("major: commit")
+		td.Repo.createGitTag("v2.0.0-broken")
 
-		// expected: version bump due to major change + prerelease data
-		v, err := td.Analyzer.GetNextVersion()
+		v, err := td.Analyzer.GetCurrentVersion()
 
 		require.Nil(err)
-		require.Equal(Version{Major: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+		require.Equal(Version{Patch: 1}, v)
 	})
 
-	t.Run("prerelease branch, repo version diff > change", func(t *testing.T) {
+	t.Run("only considers included tags", func(t *testing.T) {
 		require := require.New(t)
-		td := createAnalyzerTestData(t)
-		// branch = prerelease
-		td.Repo.checkoutGitBranch("dev")
-		// repo version diff = minor (0.2.0 <-> 0.1.0)
-		td.Repo.createGitTag("v0.2.0")
-		td.Repo.createGitCommit("next")
-		td.Repo.createGitTag("v0.1.0")
-		// change = patch
-		td.Repo.createGitCommit("patch: commit")
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.IncludeTags = []string{"^v0\\."}
+		})
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitTag("v0.0.1")
 
-		// expected: no bump (reuse repo version) + prerelease data
-		v, err := td.Analyzer.GetNextVersion()
+		v, err := td.Analyzer.GetCurrentVersion()
 
 		require.Nil(err)
-		require.Equal(Version{Minor: 2, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+		require.Equal(Version{Patch: 1}, v)
 	})
 
-	t.Run("prerelease branch, reset prerelease count", func(t *testing.T) {
+	t.Run("include tags match before 'v' prefix is stripped", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.IncludeTags = []string{"^0\\."}
+		})
+		td.Repo.createGitTag("v0.0.1")
+
+		v, err := td.Analyzer.GetCurrentVersion()
+
+		require.Nil(err)
+		require.Equal(Version{}, v)
+	})
+}
+
+func TestCompileGlob(t *testing.T) {
+	t.Run("double star", func(t *testing.T) {
+		require := require.New(t)
+
+		re, err := compileGlob("services/foo/**")
+
+		require.Nil(err)
+		require.True(re.MatchString("services/foo/a.txt"))
+		require.True(re.MatchString("services/foo/b/c.txt"))
+		require.False(re.MatchString("services/foobar/a.txt"))
+	})
+
+	t.Run("leading double star", func(t *testing.T) {
+		require := require.New(t)
+
+		re, err := compileGlob("**/a.txt")
+
+		require.Nil(err)
+		require.True(re.MatchString("a.txt"))
+		require.True(re.MatchString("services/foo/a.txt"))
+		require.False(re.MatchString("services/foo/b.txt"))
+	})
+
+	t.Run("single star", func(t *testing.T) {
+		require := require.New(t)
+
+		re, err := compileGlob("services/*/a.txt")
+
+		require.Nil(err)
+		require.True(re.MatchString("services/foo/a.txt"))
+		require.False(re.MatchString("services/foo/b/a.txt"))
+	})
+
+	t.Run("question mark", func(t *testing.T) {
+		require := require.New(t)
+
+		re, err := compileGlob("?.txt")
+
+		require.Nil(err)
+		require.True(re.MatchString("a.txt"))
+		require.False(re.MatchString("ab.txt"))
+	})
+}
+
+func TestNewAnalyzer(t *testing.T) {
+	t.Run("fails on invalid include tag pattern", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			IncludeTags: []string{"("},
+		})
+
+		require.ErrorContains(err, "missing closing )")
+	})
+
+	t.Run("fails on invalid exclude author pattern", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			ExcludeAuthors: []string{"("},
+		})
+
+		require.ErrorContains(err, "missing closing )")
+	})
+
+	t.Run("warns on duplicate rule branch pattern", func(t *testing.T) {
+		require := require.New(t)
+		b := &bytes.Buffer{}
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			Logger: slog.New(slog.NewTextHandler(b, nil)),
+			Rules:  []Rule{{Branch: "main"}, {Branch: "main", PrereleaseToken: "rc"}},
+		})
+
+		require.Nil(err)
+		require.Contains(b.String(), "level=WARN msg=\"duplicate rule branch pattern main\"")
+	})
+
+	t.Run("strict fails on duplicate rule branch pattern", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			Rules:  []Rule{{Branch: "main"}, {Branch: "main", PrereleaseToken: "rc"}},
+			Strict: true,
+		})
+
+		require.ErrorContains(err, "duplicate rule branch pattern main")
+	})
+
+	t.Run("fails on invalid rule min version", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			Rules: []Rule{{Branch: "release/2.x", MinVersion: "2.x"}},
+		})
+
+		require.ErrorContains(err, "invalid min version for rule release/2.x")
+	})
+
+	t.Run("fails on prerelease rule min version", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			Rules: []Rule{{Branch: "release/2.x", MinVersion: "2.0.0-rc.1"}},
+		})
+
+		require.ErrorContains(err, "invalid min version for rule release/2.x: 2.0.0-rc.1 is not a release")
+	})
+
+	t.Run("fails on invalid rule max change", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			Rules: []Rule{{Branch: "release/1.2.x", MaxChange: "prerelease"}},
+		})
+
+		require.ErrorContains(err, "invalid max change for rule release/1.2.x: prerelease")
+	})
+
+	t.Run("fails on invalid scheme", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzer(&AnalyzerOpts{
+			Scheme: "invalid",
+		})
+
+		require.ErrorContains(err, "invalid scheme")
+	})
+
+	t.Run("from opts", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("v1.0.0")
+		g, err := NewGit(&GitOpts{Path: d})
+		require.Nil(err)
+		p, err := NewParser("default", &ParserOpts{})
+		require.Nil(err)
+
+		a, err := NewAnalyzer(&AnalyzerOpts{
+			Git:    g,
+			Parser: p,
+			Rules:  []Rule{{Branch: "master"}},
+		})
+		require.Nil(err)
+		v, err := a.GetCurrentVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1}, v)
+	})
+
+	t.Run("from config", func(t *testing.T) {
+		require := require.New(t)
+		wd, err := os.Getwd()
+		require.Nil(err)
+		d, r := createGitRepo(t)
+		err = os.Chdir(d)
+		require.Nil(err)
+		t.Cleanup(func() {
+			os.Chdir(wd)
+		})
+		r.createGitCommit("initial")
+		r.createGitTag("v1.0.0")
+
+		a, err := NewAnalyzerFromConfig(&Config{
+			Rules: []Rule{{Branch: "master"}},
+		}, nil)
+		require.Nil(err)
+		v, err := a.GetCurrentVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1}, v)
+	})
+
+	t.Run("from config fails on invalid parser", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewAnalyzerFromConfig(&Config{Parser: "invalid"}, nil)
+
+		require.ErrorContains(err, "invalid parser type")
+	})
+}
+
+func TestAnalyzerListVersions(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		// branch = prerelease
-		td.Repo.checkoutGitBranch("dev")
-		// repo version does not match prerelease token
-		// repo version diff = minor (0.2.0-other.1 <-> 0.1.0)
-		td.Repo.createGitTag("v0.2.0-other.1")
-		td.Repo.createGitCommit("next")
-		td.Repo.createGitTag("v0.1.0")
-		// change = patch
-		td.Repo.createGitCommit("patch: commit")
 
-		// expected: no bump (reuse repo version) + reset prerelease count
-		v, err := td.Analyzer.GetNextVersion()
+		tvs, err := td.Analyzer.ListVersions()
 
 		require.Nil(err)
-		require.Equal(Version{Minor: 2, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+		require.Equal(0, len(tvs))
 	})
 
-	t.Run("prerelease branch, increment prerelease count", func(t *testing.T) {
+	t.Run("sorted with tags preserved", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		// branch = prerelease
-		td.Repo.checkoutGitBranch("dev")
-		// repo version matching prerelease token
-		// repo version diff = minor (0.2.0-rc.1 <-> 0.1.0)
-		td.Repo.createGitTag("v0.2.0-rc.1")
-		td.Repo.createGitCommit("next")
 		td.Repo.createGitTag("v0.1.0")
-		// change = patch
-		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitTag("v1.0.0-rc.1")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitTag("v1.0.0-rc.2")
+		td.Repo.createGitTag("other")
 
-		// expected: no bump (reuse repo version) + increment prerelease count
-		v, err := td.Analyzer.GetNextVersion()
+		tvs, err := td.Analyzer.ListVersions()
 
 		require.Nil(err)
-		require.Equal(Version{Minor: 2, Prerelease: Prerelease{Token: "rc", Count: 2}}, v)
+		require.Equal([]TaggedVersion{
+			{Tag: "v1.0.0", Version: Version{Major: 1}},
+			{Tag: "v1.0.0-rc.2", Version: Version{Major: 1, Prerelease: Prerelease{Token: "rc", Count: 2}}},
+			{Tag: "v1.0.0-rc.1", Version: Version{Major: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}},
+			{Tag: "v0.1.0", Version: Version{Minor: 1}},
+		}, tvs)
 	})
+}
 
-	t.Run("release, repo version release", func(t *testing.T) {
+func TestAnalyzerTagRemote(t *testing.T) {
+	t.Run("sources versions from remote", func(t *testing.T) {
+		require := require.New(t)
+		rd, rr := createGitRepo(t)
+		rr.createGitCommit("initial")
+		rr.createGitTag("v2.0.0")
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.TagRemote = rd
+		})
+		td.Repo.createGitTag("v1.0.0")
+
+		v, err := td.Analyzer.GetCurrentVersion()
+		require.Nil(err)
+		tvs, err := td.Analyzer.ListVersions()
+		require.Nil(err)
+
+		require.Equal(Version{Major: 2}, v)
+		require.Equal([]TaggedVersion{{Tag: "v2.0.0", Version: Version{Major: 2}}}, tvs)
+	})
+}
+
+func TestAnalyzerTagPrefix(t *testing.T) {
+	t.Run("separates version streams", func(t *testing.T) {
+		require := require.New(t)
+		fa := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.TagPrefix = "foo/v"
+		})
+		fa.Repo.createGitTag("foo/v1.2.3")
+		fa.Repo.createGitTag("bar/v0.1.0")
+		fa.Repo.createGitTag("v2.0.0")
+		ba, err := NewAnalyzer(&AnalyzerOpts{
+			Git:       fa.Analyzer.git,
+			Parser:    fa.Analyzer.parser,
+			TagPrefix: "bar/v",
+		})
+		require.Nil(err)
+
+		fv, err := fa.Analyzer.GetCurrentVersion()
+		require.Nil(err)
+		bv, err := ba.GetCurrentVersion()
+		require.Nil(err)
+
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3}, fv)
+		require.Equal("foo/v1.2.3", fv.Tag("foo/v"))
+		require.Equal(Version{Minor: 1}, bv)
+		require.Equal("bar/v0.1.0", bv.Tag("bar/v"))
+	})
+
+	t.Run("formats git versions with prefix", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.TagPrefix = "release-"
+		})
+		v := Version{Major: 1, Minor: 2, Patch: 3}
+
+		require.Equal("release-1.2.3", td.Analyzer.FormatVersion(v, "git"))
+		require.Equal("1.2.3", td.Analyzer.FormatVersion(v, "docker"))
+		require.Equal("v1.2.3", v.String("git"))
+	})
+
+	t.Run("default prefix ignores prefixed tags", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		// branch = release
+		td.Repo.createGitTag("foo/v1.2.3")
+
+		v, err := td.Analyzer.GetCurrentVersion()
+
+		require.Nil(err)
+		require.Equal(Version{}, v)
+	})
+
+	t.Run("next version uses prefixed ancestor tags", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.TagPrefix = "foo/v"
+		})
 		td.Repo.checkoutGitBranch("main")
-		// repo version is release
-		td.Repo.createGitTag("v0.1.0")
-		// change = patch
+		td.Repo.createGitTag("foo/v1.2.3")
 		td.Repo.createGitCommit("patch: commit")
 
-		// expected: version bump from repo version
 		v, err := td.Analyzer.GetNextVersion()
 
 		require.Nil(err)
-		require.Equal(Version{Minor: 1, Patch: 1}, v)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 4}, v)
 	})
+}
 
-	t.Run("release, repo version prerelease, repo version diff < change", func(t *testing.T) {
+func TestAnalyzerGetPromotedVersion(t *testing.T) {
+	t.Run("promotes prerelease", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		// branch = release
-		td.Repo.checkoutGitBranch("main")
-		// repo version prerelease, repo version diff = minor
-		td.Repo.createGitTag("v0.2.0-rc.1")
-		td.Repo.createGitCommit("next")
-		td.Repo.createGitTag("v0.1.0")
-		// change = major
-		td.Repo.createGitCommit("major: commit")
+		td.Repo.createGitTag("v1.1.0")
+		td.Repo.createGitCommit("minor: commit")
+		td.Repo.createGitTag("v1.2.0-rc.3+meta")
 
-		// expected: repo version bump from change
-		v, err := td.Analyzer.GetNextVersion()
+		v, err := td.Analyzer.GetPromotedVersion()
 
 		require.Nil(err)
-		require.Equal(Version{Major: 1}, v)
+		require.Equal(Version{Major: 1, Minor: 2}, v)
 	})
 
-	t.Run("release, repo version prerelease, diff > change", func(t *testing.T) {
+	t.Run("fails on release", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		// branch = release
-		td.Repo.checkoutGitBranch("main")
-		// repo version prerelease, repo version diff = minor
-		td.Repo.createGitTag("v0.2.0-rc.1")
-		td.Repo.createGitCommit("next")
-		td.Repo.createGitTag("v0.1.0")
-		// change = patch
-		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitTag("v1.2.0-rc.3")
+		td.Repo.createGitCommit("minor: commit")
+		td.Repo.createGitTag("v1.2.0")
 
-		// expected: convert repo version to release and use
-		v, err := td.Analyzer.GetNextVersion()
+		_, err := td.Analyzer.GetPromotedVersion()
+
+		require.ErrorContains(err, "current version 1.2.0 is not a prerelease")
+	})
+}
+
+func TestAnalyzerGetLastRelease(t *testing.T) {
+	t.Run("defaults to 0.0.0", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+
+		v, h, err := td.Analyzer.GetLastRelease()
 
 		require.Nil(err)
-		require.Equal(Version{Minor: 2}, v)
+		require.Equal(Version{}, v)
+		require.Equal("", h)
 	})
 
-	t.Run("metadata + capture groups", func(t *testing.T) {
+	t.Run("ignores prereleases", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		td.Repo.checkoutGitBranch("other/branch")
-		td.Repo.createGitCommit("patch: initial")
+		h := td.Repo.createGitCommit("release")
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitCommit("prerelease")
+		td.Repo.createGitTag("v1.1.0-rc.1")
 
-		v, err := td.Analyzer.GetNextVersion()
+		cv, err := td.Analyzer.GetCurrentVersion()
+		require.Nil(err)
+		v, lh, err := td.Analyzer.GetLastRelease()
 
 		require.Nil(err)
-		require.Equal(Version{Patch: 1, Prerelease: Prerelease{Token: "other-branch", Count: 1}, Metadata: "other-branch"}, v)
+		require.Equal(Version{Major: 1, Minor: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}, cv)
+		require.Equal(Version{Major: 1}, v)
+		require.Equal(h, lh)
 	})
 
-	t.Run("fail if no change", func(t *testing.T) {
+	t.Run("ignores unreachable releases", func(t *testing.T) {
 		require := require.New(t)
 		td := createAnalyzerTestData(t)
-		td.Repo.checkoutGitBranch("main")
+		h := td.Repo.createGitCommit("release")
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.checkoutGitBranch("other")
+		td.Repo.createGitCommit("other release")
+		td.Repo.createGitTag("v2.0.0")
+		td.Repo.checkoutGitBranch("master")
 
-		_, err := td.Analyzer.GetNextVersion()
+		cv, err := td.Analyzer.GetCurrentVersion()
+		require.Nil(err)
+		v, lh, err := td.Analyzer.GetLastRelease()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2}, cv)
+		require.Equal(Version{Major: 1}, v)
+		require.Equal(h, lh)
+	})
+}
+
+func TestAnalyzerOverrideNextVersion(t *testing.T) {
+	t.Run("forward override", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v0.1.0")
+
+		v, err := td.Analyzer.OverrideNextVersion("1.0.0", false)
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1}, v)
+	})
+
+	t.Run("rejects backward override", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v0.1.0")
+
+		_, err := td.Analyzer.OverrideNextVersion("0.0.9", false)
+
+		require.ErrorContains(err, "version 0.0.9 is not greater than current version 0.1.0")
+	})
+
+	t.Run("rejects equal override", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v0.1.0")
+
+		_, err := td.Analyzer.OverrideNextVersion("0.1.0", false)
+
+		require.ErrorContains(err, "not greater than current version")
+	})
+
+	t.Run("forces backward override", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v0.1.0")
+
+		v, err := td.Analyzer.OverrideNextVersion("0.0.9", true)
+
+		require.Nil(err)
+		require.Equal(Version{Patch: 9}, v)
+	})
+
+	t.Run("rejects invalid version", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+
+		_, err := td.Analyzer.OverrideNextVersion("v1.0.0", false)
+
+		require.ErrorContains(err, "invalid version string")
+	})
+}
+
+func TestAnalyzerGetMergePreviewVersion(t *testing.T) {
+	t.Run("previews feature branch merged into target", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.checkoutGitBranch("pr-123")
+		td.Repo.createGitCommit("minor: feature")
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitCommit("patch: fix")
+
+		v, err := td.Analyzer.GetMergePreviewVersion("main", "pr-123")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1}, v)
+	})
+
+	t.Run("uses target rule from feature branch", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitCommit("minor: feature")
+		td.Repo.checkoutGitBranch("pr-123")
+		td.Repo.createGitCommit("patch: fix")
+
+		v, err := td.Analyzer.GetMergePreviewVersion("main", "pr-123")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1}, v)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.checkoutGitBranch("pr-123")
+		td.Repo.createGitCommit("docs: readme")
+
+		_, err := td.Analyzer.GetMergePreviewVersion("main", "pr-123")
+
+		require.ErrorContains(err, "version unchanged")
+	})
+}
+
+func TestAnalyzerChangeBetween(t *testing.T) {
+	t.Run("max change between tags", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitCommit("minor: commit")
+		td.Repo.createGitTag("v1.1.0")
+		td.Repo.createGitCommit("major: commit")
+		td.Repo.createGitTag("v2.0.0")
+
+		vc, err := td.Analyzer.ChangeBetween("v1.0.0", "v1.1.0")
+
+		require.Nil(err)
+		require.Equal("minor", vc.Value)
+	})
+
+	t.Run("no change between tags", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitCommit("other: commit")
+		td.Repo.createGitTag("v1.0.1")
+
+		vc, err := td.Analyzer.ChangeBetween("v1.0.0", "v1.0.1")
+
+		require.Nil(err)
+		require.Equal("none", vc.Value)
+	})
+
+	t.Run("fails if from is not an ancestor", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitCommit("minor: commit")
+		td.Repo.createGitTag("v1.1.0")
+
+		_, err := td.Analyzer.ChangeBetween("v1.1.0", "v1.0.0")
+
+		require.ErrorContains(err, "v1.1.0 is not an ancestor of v1.0.0")
+	})
+
+	t.Run("fails on unknown revision", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+
+		_, err := td.Analyzer.ChangeBetween("v9.0.0", "HEAD")
+
+		require.NotNil(err)
+	})
+}
+
+func TestAnalyzerIsReleased(t *testing.T) {
+	t.Run("head not tagged", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitCommit("patch: commit")
+
+		r, v, err := td.Analyzer.IsReleased()
+
+		require.Nil(err)
+		require.False(r)
+		require.Equal(Version{}, v)
+	})
+
+	t.Run("head tagged with prerelease", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0-rc.1")
+
+		r, _, err := td.Analyzer.IsReleased()
+
+		require.Nil(err)
+		require.False(r)
+	})
+
+	t.Run("head tagged with release", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitTag("v1.0.0-rc.1")
+		td.Repo.createGitTag("v1.0.0")
+
+		r, v, err := td.Analyzer.IsReleased()
+
+		require.Nil(err)
+		require.True(r)
+		require.Equal(Version{Major: 1}, v)
+	})
+}
+
+func TestAnalyzerVerifyHeadSignature(t *testing.T) {
+	t.Run("signed head", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		e, kr := createPGPKey(t)
+		td.Repo.createSignedGitCommit("signed", e)
+
+		err := td.Analyzer.VerifyHeadSignature(kr)
+
+		require.Nil(err)
+	})
+
+	t.Run("unsigned head", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		_, kr := createPGPKey(t)
+		h := td.Repo.createGitCommit("unsigned")
+
+		err := td.Analyzer.VerifyHeadSignature(kr)
+
+		require.ErrorContains(err, fmt.Sprintf("commit %s is not signed by a trusted key", h))
+	})
+}
+
+func TestAnalyzerCheck(t *testing.T) {
+	t.Run("usable repo", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitTag("other")
+
+		crs := td.Analyzer.Check()
+
+		require.Len(crs, 3)
+		for _, cr := range crs {
+			require.True(cr.OK, cr.Message)
+		}
+		require.Equal("1 of 2 tag(s) are versions (current: 1.0.0)", crs[2].Message)
+	})
+
+	t.Run("no matching rule", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = []Rule{{Branch: "main"}}
+		})
+
+		crs := td.Analyzer.Check()
+
+		require.Equal("rule", crs[1].Name)
+		require.False(crs[1].OK)
+	})
+
+	t.Run("no version tags", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+
+		crs := td.Analyzer.Check()
+
+		require.True(crs[2].OK)
+		require.Equal("no version tags (of 0 tag(s)) - versions start from 0.0.0", crs[2].Message)
+	})
+}
+
+func TestAnalyzerGetNextVersion(t *testing.T) {
+	t.Run("prerelease branch, repo version diff < change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = prerelease
+		td.Repo.checkoutGitBranch("dev")
+		// repo version diff = patch (0.0.2 <-> 0.0.1)
+		td.Repo.createGitTag("v0.0.2")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v0.0.1")
+		// change = major
+		td.Repo.createGitCommit("major: commit")
+
+		// expected: version bump due to major change + prerelease data
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("prerelease branch, repo version diff > change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = prerelease
+		td.Repo.checkoutGitBranch("dev")
+		// repo version diff = minor (0.2.0-rc.1 <-> 0.1.0)
+		td.Repo.createGitTag("v0.2.0-rc.1")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v0.1.0")
+		// change = patch
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: no bump (reuse repo version) + prerelease data
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 2, Prerelease: Prerelease{Token: "rc", Count: 2}}, v)
+	})
+
+	t.Run("prerelease branch, reset prerelease count", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = prerelease
+		td.Repo.checkoutGitBranch("dev")
+		// repo version does not match prerelease token
+		// repo version diff = minor (0.2.0-other.1 <-> 0.1.0)
+		td.Repo.createGitTag("v0.2.0-other.1")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v0.1.0")
+		// change = patch
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: no bump (reuse repo version) + reset prerelease count
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 2, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("prerelease branch, increment prerelease count", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = prerelease
+		td.Repo.checkoutGitBranch("dev")
+		// repo version matching prerelease token
+		// repo version diff = minor (0.2.0-rc.1 <-> 0.1.0)
+		td.Repo.createGitTag("v0.2.0-rc.1")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v0.1.0")
+		// change = patch
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: no bump (reuse repo version) + increment prerelease count
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 2, Prerelease: Prerelease{Token: "rc", Count: 2}}, v)
+	})
+
+	t.Run("prerelease branch, custom prerelease start", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			ps := 0
+			o.PrereleaseStart = &ps
+		})
+		td.Repo.checkoutGitBranch("dev")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: first prerelease uses configured start
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1, Prerelease: Prerelease{Token: "rc", Count: 0}}, v)
+
+		td.Repo.createGitTag("v0.1.1-rc.0")
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: subsequent prerelease increments from start
+		v, err = td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("prerelease branch, diverged before latest release", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		// branch = prerelease (diverges from v0.1.0)
+		td.Repo.checkoutGitBranch("dev")
+		// latest release on main
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitCommit("major: commit")
+		td.Repo.createGitTag("v1.0.0")
+		// change = patch
+		td.Repo.checkoutGitBranch("dev")
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: version bumped past latest release (rather than 1.0.0-rc.1)
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Patch: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("release, repo version release", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = release
+		td.Repo.checkoutGitBranch("main")
+		// repo version is release
+		td.Repo.createGitTag("v0.1.0")
+		// change = patch
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: version bump from repo version
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("release, repo version prerelease, repo version diff < change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = release
+		td.Repo.checkoutGitBranch("main")
+		// repo version prerelease, repo version diff = minor
+		td.Repo.createGitTag("v0.2.0-rc.1")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v0.1.0")
+		// change = major
+		td.Repo.createGitCommit("major: commit")
+
+		// expected: repo version bump from change
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1}, v)
+	})
+
+	t.Run("release, repo version prerelease, diff > change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		// branch = release
+		td.Repo.checkoutGitBranch("main")
+		// repo version prerelease, repo version diff = minor
+		td.Repo.createGitTag("v0.2.0-rc.1")
+		td.Repo.createGitCommit("next")
+		td.Repo.createGitTag("v0.1.0")
+		// change = patch
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: convert repo version to release and use
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 2}, v)
+	})
+
+	t.Run("metadata + capture groups", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.checkoutGitBranch("other/branch")
+		td.Repo.createGitCommit("patch: initial")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Patch: 1, Prerelease: Prerelease{Token: "other-branch", Count: 1}, Metadata: "other-branch"}, v)
+	})
+
+	t.Run("min version raises version to floor", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/2\\.x$", MinVersion: "2.0.0"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v1.9.0")
+		td.Repo.checkoutGitBranch("release/2.x")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2}, v)
+	})
+
+	t.Run("min version raises prerelease version to floor", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/2\\.x$", MinVersion: "2.0.0", PrereleaseToken: "rc"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v1.9.0")
+		td.Repo.checkoutGitBranch("release/2.x")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitTag("v2.0.0-rc.1")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2, Prerelease: Prerelease{Token: "rc", Count: 2}}, v)
+	})
+
+	t.Run("min version ignored above floor", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/2\\.x$", MinVersion: "2.0.0"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v2.1.0")
+		td.Repo.checkoutGitBranch("release/2.x")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2, Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("max change clamps larger change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/1\\.2\\.x$", MaxChange: "patch"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v1.2.3")
+		td.Repo.checkoutGitBranch("release/1.2.x")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitCommit("minor: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 4}, v)
+	})
+
+	t.Run("max change ignores newer releases on other branches", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/1\\.2\\.x$", MaxChange: "patch"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v1.2.3")
+		td.Repo.checkoutGitBranch("release/1.2.x")
+		td.Repo.checkoutGitBranch("master")
+		td.Repo.createGitCommit("minor: main commit")
+		td.Repo.createGitTag("v1.3.0")
+		td.Repo.checkoutGitBranch("release/1.2.x")
+		td.Repo.createGitCommit("minor: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 4}, v)
+	})
+
+	t.Run("max change avoids existing releases in release line", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/1\\.2\\.x$", MaxChange: "patch"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v1.2.3")
+		td.Repo.checkoutGitBranch("release/1.2.x")
+		td.Repo.checkoutGitBranch("other")
+		td.Repo.createGitCommit("patch: other commit")
+		td.Repo.createGitTag("v1.2.4")
+		td.Repo.checkoutGitBranch("master")
+		td.Repo.createGitCommit("minor: main commit")
+		td.Repo.createGitTag("v1.3.0")
+		td.Repo.checkoutGitBranch("release/1.2.x")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 5}, v)
+	})
+
+	t.Run("max change allows smaller change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/1\\.x$", MaxChange: "minor"}}, o.Rules...)
+		})
+		td.Repo.createGitTag("v1.2.3")
+		td.Repo.checkoutGitBranch("release/1.x")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 4}, v)
+	})
+
+	t.Run("max change fails on larger change when strict", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Rules = append([]Rule{{Branch: "^release/1\\.2\\.x$", MaxChange: "patch"}}, o.Rules...)
+			o.Strict = true
+		})
+		td.Repo.createGitTag("v1.2.3")
+		td.Repo.checkoutGitBranch("release/1.2.x")
+		td.Repo.createGitCommit("minor: commit")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "change minor exceeds max change patch for rule ^release/1\\.2\\.x$")
+	})
+
+	t.Run("zero major semantics, major change on 0.x", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.ZeroMajorSemantics = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.5.0")
+		td.Repo.createGitCommit("major: commit")
+
+		// expected: major change downgraded to minor
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 6}, v)
+	})
+
+	t.Run("zero major semantics, minor change on 0.x", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.ZeroMajorSemantics = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.5.0")
+		td.Repo.createGitCommit("minor: commit")
+
+		// expected: minor change downgraded to patch
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 5, Patch: 1}, v)
+	})
+
+	t.Run("zero major semantics disabled, major change on 0.x", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.5.0")
+		td.Repo.createGitCommit("major: commit")
+
+		// expected: major change bumps major version
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1}, v)
+	})
+
+	t.Run("zero major semantics, major change on 1.x", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.ZeroMajorSemantics = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v1.0.0")
+		td.Repo.createGitCommit("major: commit")
+
+		// expected: change unaffected outside of 0.x
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2}, v)
+	})
+
+	t.Run("calver, first version", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Scheme = "calver"
+			o.Now = func() time.Time { return time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC) }
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2024, Minor: 1}, v)
+	})
+
+	t.Run("calver, within month", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Scheme = "calver"
+			o.Now = func() time.Time { return time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC) }
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v2024.1.1")
+		td.Repo.createGitCommit("major: commit")
+
+		// expected: patch increments regardless of change
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2024, Minor: 1, Patch: 2}, v)
+	})
+
+	t.Run("calver, month boundary", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Scheme = "calver"
+			o.Now = func() time.Time { return time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC) }
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v2024.1.1")
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: patch resets on new month
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2024, Minor: 2}, v)
+	})
+
+	t.Run("calver, prerelease branch within month", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Scheme = "calver"
+			o.Now = func() time.Time { return time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC) }
+		})
+		td.Repo.checkoutGitBranch("dev")
+		td.Repo.createGitTag("v2024.1.1")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitTag("v2024.1.2-rc.1")
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: prerelease count increments on existing prerelease
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2024, Minor: 1, Patch: 2, Prerelease: Prerelease{Token: "rc", Count: 2}}, v)
+	})
+
+	t.Run("calver, release branch promotes prerelease", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Scheme = "calver"
+			o.Now = func() time.Time { return time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC) }
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v2024.1.2-rc.1")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2024, Minor: 1, Patch: 2}, v)
+	})
+
+	t.Run("path filter ignores commits outside filter", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.PathFilter = []string{"services/foo/**"}
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommitWithFiles("patch: foo commit", "services/foo/a.txt")
+		td.Repo.createGitCommitWithFiles("major: bar commit", "services/bar/a.txt")
+
+		// expected: only the commit within the path filter contributes
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("path filter fails if no matching change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.PathFilter = []string{"services/foo/**"}
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommitWithFiles("major: bar commit", "services/bar/a.txt")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "version unchanged")
+	})
+
+	t.Run("exclude authors ignores bot commits", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.ExcludeAuthors = []string{"\\[bot\\]"}
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitCommitWithAuthor("major: bump dep", "dependabot[bot]", "bot@example.com")
+
+		// expected: bot commit does not contribute
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("exclude authors fails if only bot commits", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.ExcludeAuthors = []string{"<bot@example.com>$"}
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommitWithAuthor("patch: bump dep", "renovate", "bot@example.com")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "version unchanged")
+	})
+
+	t.Run("exclude trailers ignores commits with trailer", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.ExcludeTrailers = []string{"Skip-Release:"}
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createGitCommit("major: commit\n\nSkip-Release: true")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("chain parser, second parser contributes", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("chain", &ParserOpts{
+			Parsers: []ParserSpec{
+				{Tags: map[string]string{"patch:": "patch"}},
+				{Tags: map[string]string{"feat:": "minor"}},
+			},
+		})
+		require.Nil(err)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Parser = p
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: legacy commit")
+		td.Repo.createGitCommit("feat: new commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 2}, v)
+	})
+
+	t.Run("notes escalate change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.UseNotes = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+		h := td.Repo.createGitCommit("other: commit")
+		td.Repo.createGitNote(h, "major: annotated")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1}, v)
+	})
+
+	t.Run("notes ignored by default", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+		h := td.Repo.createGitCommit("other: commit")
+		td.Repo.createGitNote(h, "major: annotated")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("broken notes ignored by default", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createBrokenGitNotes()
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("broken notes fail when used", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.UseNotes = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+		td.Repo.createBrokenGitNotes()
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.NotNil(err)
+	})
+
+	t.Run("transform appends metadata", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Transform = func(v Version) (Version, error) {
+				v.Metadata = v.Metadata + "-build-5"
+				return v, nil
+			}
+		})
+		td.Repo.checkoutGitBranch("other")
+		td.Repo.createGitCommit("patch: commit")
+
+		// expected: transform runs after rule metadata
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Patch: 1, Prerelease: Prerelease{Token: "other", Count: 1}, Metadata: "other-build-5"}, v)
+	})
+
+	t.Run("transform error", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Transform = func(v Version) (Version, error) {
+				return Version{}, fmt.Errorf("transform failed")
+			}
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitCommit("patch: commit")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "transform failed")
+	})
+
+	t.Run("warns on shallow repository without release", func(t *testing.T) {
+		require := require.New(t)
+		b := &bytes.Buffer{}
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Logger = slog.New(slog.NewTextHandler(b, nil))
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.makeShallow(td.Repo.createGitCommit("patch: commit"))
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Patch: 1}, v)
+		require.Contains(b.String(), "level=WARN msg=\"shallow repository")
+	})
+
+	t.Run("strict fails on shallow repository without release", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Strict = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.makeShallow(td.Repo.createGitCommit("patch: commit"))
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "shallow repository")
+	})
+
+	t.Run("shallow repository with release", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Strict = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.makeShallow(td.Repo.createGitCommit("patch: commit"))
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("warns if no commits since release", func(t *testing.T) {
+		require := require.New(t)
+		b := &bytes.Buffer{}
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Logger = slog.New(slog.NewTextHandler(b, nil))
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "version unchanged")
+		require.Contains(b.String(), "level=WARN msg=\"no commits since last release\"")
+	})
+
+	t.Run("warns if no matching commits since release", func(t *testing.T) {
+		require := require.New(t)
+		b := &bytes.Buffer{}
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.Logger = slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("other: first\n\nbody")
+		td.Repo.createGitCommit("other: second")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "version unchanged")
+		require.Contains(b.String(), "level=WARN msg=\"2 commit(s) since last release - none matched a version bump tag\"")
+		require.Contains(b.String(), "(unmatched: other: first)")
+		require.Contains(b.String(), "(unmatched: other: second)")
+	})
+
+	t.Run("build on unchanged, release branch", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.BuildOnUnchanged = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		h := td.Repo.createGitCommit("chore: commit")
+
+		v, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(Version{Minor: 1, Metadata: h[:7]}, v)
+	})
+
+	t.Run("build on unchanged, reports unchanged", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.BuildOnUnchanged = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("chore: commit")
+
+		_, c, err := td.Analyzer.GetNextVersionChanged()
+
+		require.Nil(err)
+		require.False(c)
+	})
+
+	t.Run("changed version reports changed", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.BuildOnUnchanged = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("patch: commit")
+
+		v, c, err := td.Analyzer.GetNextVersionChanged()
+
+		require.Nil(err)
+		require.True(c)
+		require.Equal(Version{Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("build on unchanged, unique per commit", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.BuildOnUnchanged = true
+		})
+		td.Repo.checkoutGitBranch("main")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("chore: commit")
+		v1, err := td.Analyzer.GetNextVersion()
+		require.Nil(err)
+		td.Repo.createGitCommit("chore: commit")
+
+		v2, err := td.Analyzer.GetNextVersion()
+
+		require.Nil(err)
+		require.Equal(0, v1.Compare(v2))
+		require.NotEqual(v1.Metadata, v2.Metadata)
+	})
+
+	t.Run("build on unchanged, prerelease branch", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t, func(o *AnalyzerOpts) {
+			o.BuildOnUnchanged = true
+		})
+		td.Repo.checkoutGitBranch("dev")
+		td.Repo.createGitTag("v0.1.0")
+		td.Repo.createGitCommit("chore: commit")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "version unchanged")
+	})
+
+	t.Run("fail if no change", func(t *testing.T) {
+		require := require.New(t)
+		td := createAnalyzerTestData(t)
+		td.Repo.checkoutGitBranch("main")
+
+		_, err := td.Analyzer.GetNextVersion()
+
+		require.ErrorContains(err, "version unchanged")
+	})
+}
+
+func TestSanitizeMetadata(t *testing.T) {
+	t.Run("preserves identifier separators", func(t *testing.T) {
+		require := require.New(t)
+
+		require.Equal("build.5", SanitizeMetadata("build.5"))
+	})
+
+	t.Run("replaces invalid characters", func(t *testing.T) {
+		require := require.New(t)
+
+		require.Equal("feature-x.5", SanitizeMetadata("feature/x.5"))
+	})
+
+	t.Run("drops empty identifiers", func(t *testing.T) {
+		require := require.New(t)
+
+		require.Equal("a.b", SanitizeMetadata(".a..b."))
+	})
+}
+
+func TestComputeNextVersion(t *testing.T) {
+	createParser := func(t *testing.T) Parser {
+		t.Helper()
+		p, err := NewParser("default", &ParserOpts{
+			Tags: map[string]string{
+				"patch:": "patch",
+				"minor:": "minor",
+				"major:": "major",
+			},
+		})
+		require.Nil(t, err)
+		return p
+	}
+
+	t.Run("release, current release", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{Major: 1}, []string{"patch: a", "minor: b"}, Rule{}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1}, v)
+	})
+
+	t.Run("release, max change clamps change", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{Major: 1}, []string{"major: a"}, Rule{MaxChange: "patch"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Patch: 1}, v)
+	})
+
+	t.Run("release, min version raises version to floor", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{Major: 1}, []string{"patch: a"}, Rule{MinVersion: "2.0.0"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2}, v)
+	})
+
+	t.Run("prerelease, min version raises version to floor", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{Major: 1}, []string{"patch: a"}, Rule{MinVersion: "2.0.0", PrereleaseToken: "rc"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("min version ignored above floor", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{Major: 2, Minor: 1}, []string{"patch: a"}, Rule{MinVersion: "2.0.0"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2, Minor: 1, Patch: 1}, v)
+	})
+
+	t.Run("fails on prerelease min version", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := ComputeNextVersion(Version{Major: 1}, []string{"patch: a"}, Rule{MinVersion: "2.0.0-rc.1"}, createParser(t))
+
+		require.ErrorContains(err, "2.0.0-rc.1 is not a release")
+	})
+
+	t.Run("release, current prerelease, diff >= change", func(t *testing.T) {
+		require := require.New(t)
+		c := Version{Major: 1, Minor: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		v, err := ComputeNextVersion(c, []string{"patch: a"}, Rule{}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1}, v)
+	})
+
+	t.Run("release, current prerelease, diff < change", func(t *testing.T) {
+		require := require.New(t)
+		c := Version{Major: 1, Patch: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		v, err := ComputeNextVersion(c, []string{"minor: a"}, Rule{}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1}, v)
+	})
+
+	t.Run("prerelease, current release", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{Major: 1}, []string{"minor: a"}, Rule{PrereleaseToken: "rc"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("prerelease, current prerelease, diff >= change", func(t *testing.T) {
+		require := require.New(t)
+		c := Version{Major: 1, Minor: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		v, err := ComputeNextVersion(c, []string{"patch: a"}, Rule{PrereleaseToken: "rc"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 1, Prerelease: Prerelease{Token: "rc", Count: 2}}, v)
+	})
+
+	t.Run("prerelease, current prerelease, diff < change", func(t *testing.T) {
+		require := require.New(t)
+		c := Version{Major: 1, Minor: 1, Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		v, err := ComputeNextVersion(c, []string{"major: a"}, Rule{PrereleaseToken: "rc"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Major: 2, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ComputeNextVersion(Version{}, []string{"patch: a"}, Rule{Metadata: "build.1"}, createParser(t))
+
+		require.Nil(err)
+		require.Equal(Version{Patch: 1, Metadata: "build.1"}, v)
+	})
+
+	t.Run("fail if no change", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := ComputeNextVersion(Version{}, []string{"other: a"}, Rule{}, createParser(t))
 
 		require.ErrorContains(err, "version unchanged")
 	})
@@ -1,13 +1,20 @@
 package versionctl
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 // A GitClient represents a git client.
@@ -58,12 +65,33 @@ func (g Git) GetCurrentBranch() (string, error) {
 	return h.Name().Short(), nil
 }
 
+// Gets the hash of the current head for the local working copy.
+func (g Git) GetHeadHash() (string, error) {
+	h, err := g.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return h.Hash().String(), nil
+}
+
+// Gets the hash of the commit referred to by a git revision (e.g., tags, branches, hashes).
+func (g Git) GetHash(rev string) (string, error) {
+	h, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", err
+	}
+	return h.String(), nil
+}
+
 // A GitCommit represents data fields attached to a git commit
 // within the local working copy
 type GitCommit struct {
-	Hash    string
-	Message string
-	Tags    []string
+	AuthorEmail string
+	AuthorName  string
+	Hash        string
+	Message     string
+	Tags        []string
+	When        time.Time // the author date
 }
 
 // Stops iteration when returned within an iteration callback
@@ -78,6 +106,7 @@ func (s *StopIter) Error() string {
 // Iterates through all commits from the provided head in reverse order.
 // The callback is called for each [GitCommit] found.
 // Return &StopIter{} to stop iteration.
+// For shallow clones, iteration stops at the shallow history boundary.
 // If head is a zero value, will use the current head of the local working copy
 func (g Git) IterCommits(head string, cb func(c GitCommit) error) error {
 	// use current head if not defined
@@ -99,11 +128,17 @@ func (g Git) IterCommits(head string, cb func(c GitCommit) error) error {
 	if err != nil {
 		return err
 	}
-	ts.ForEach(func(t *plumbing.Reference) error {
-		th := t.Hash().String()
+	err = ts.ForEach(func(t *plumbing.Reference) error {
+		th, ok, err := g.peelTag(t)
+		if err != nil || !ok {
+			return err
+		}
 		htm[th] = append(htm[th], t.Name().Short())
 		return nil
 	})
+	if err != nil {
+		return err
+	}
 	// obtain commit iterator
 	ci, err := g.repo.Log(&git.LogOptions{From: *hh})
 	if err != nil {
@@ -113,9 +148,12 @@ func (g Git) IterCommits(head string, cb func(c GitCommit) error) error {
 	err = ci.ForEach(func(oc *object.Commit) error {
 		ch := oc.Hash.String()
 		c := GitCommit{
-			Hash:    ch,
-			Message: oc.Message,
-			Tags:    htm[ch],
+			AuthorEmail: oc.Author.Email,
+			AuthorName:  oc.Author.Name,
+			Hash:        ch,
+			Message:     oc.Message,
+			Tags:        htm[ch],
+			When:        oc.Author.When,
 		}
 		err := cb(c)
 		if err != nil {
@@ -125,14 +163,93 @@ func (g Git) IterCommits(head string, cb func(c GitCommit) error) error {
 	})
 	if err != nil {
 		_, stopIter := err.(*StopIter)
-		if !stopIter {
-			return err
+		if stopIter {
+			return nil
+		}
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			// commits beyond a shallow boundary are missing - stop iteration
+			s, serr := g.IsShallow()
+			if serr != nil {
+				return serr
+			}
+			if s {
+				g.logger.Debug("reached shallow history boundary")
+				return nil
+			}
 		}
+		return err
 	}
 	return nil
 }
 
-// Lists all tags for the local working copy
+// Gets the note attached to the commit with the provided hash (via 'refs/notes/commits').
+// Returns an empty string if the commit has no note (or if no notes exist).
+func (g Git) GetNote(hash string) (string, error) {
+	r, err := g.repo.Reference(plumbing.ReferenceName("refs/notes/commits"), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	c, err := g.repo.CommitObject(r.Hash())
+	if err != nil {
+		return "", err
+	}
+	t, err := c.Tree()
+	if err != nil {
+		return "", err
+	}
+	// notes trees may fan out paths (e.g., 'ab/cdef...')
+	for p := hash; len(p) > 2; p = p[2:] {
+		f, err := t.File(p)
+		if err == nil {
+			return f.Contents()
+		}
+		t, err = t.Tree(p[:2])
+		if errors.Is(err, object.ErrDirectoryNotFound) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Determines whether the local working copy is a shallow clone (i.e., has truncated history).
+func (g Git) IsShallow() (bool, error) {
+	hs, err := g.repo.Storer.Shallow()
+	if err != nil {
+		return false, err
+	}
+	return len(hs) > 0, nil
+}
+
+// Resolves a tag reference (lightweight or annotated) to the hash of the commit it points to.
+// Returns false if the tag does not point to a commit (e.g., a tag of a blob or tree) or its target is missing.
+func (g Git) peelTag(r *plumbing.Reference) (string, bool, error) {
+	h := r.Hash()
+	for {
+		o, err := g.repo.Object(plumbing.AnyObject, h)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			g.logger.Debug(fmt.Sprintf("tag target not found: %s", r.Name().Short()))
+			return "", false, nil
+		} else if err != nil {
+			return "", false, err
+		}
+		switch to := o.(type) {
+		case *object.Commit:
+			return to.Hash.String(), true, nil
+		case *object.Tag:
+			// annotated tag - peel to target
+			h = to.Target
+		default:
+			g.logger.Debug(fmt.Sprintf("tag does not point to commit: %s (%s)", r.Name().Short(), o.Type()))
+			return "", false, nil
+		}
+	}
+}
+
+// Lists all tags (pointing to commits) for the local working copy
 func (g Git) ListTags() ([]string, error) {
 	// obtain tag iterator
 	i, err := g.repo.Tags()
@@ -142,6 +259,10 @@ func (g Git) ListTags() ([]string, error) {
 	// iterate over and collect all tag names
 	t := []string{}
 	err = i.ForEach(func(r *plumbing.Reference) error {
+		_, ok, err := g.peelTag(r)
+		if err != nil || !ok {
+			return err
+		}
 		t = append(t, r.Name().Short())
 		return nil
 	})
@@ -150,3 +271,94 @@ func (g Git) ListTags() ([]string, error) {
 	}
 	return t, nil
 }
+
+// Verifies the PGP signature of the commit with the provided hash against an armored keyring.
+// Returns false if the commit is unsigned or if its signature cannot be verified by the keyring.
+// Returns an error if the keyring is invalid or if the commit cannot be found.
+func (g Git) VerifyCommitSignature(hash string, keyring string) (bool, error) {
+	// parse keyring up-front - distinguishes invalid keyrings from unverifiable signatures
+	_, err := openpgp.ReadArmoredKeyRing(strings.NewReader(keyring))
+	if err != nil {
+		return false, fmt.Errorf("invalid keyring: %w", err)
+	}
+	c, err := g.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return false, err
+	}
+	if c.PGPSignature == "" {
+		g.logger.Debug(fmt.Sprintf("commit unsigned: %s", hash))
+		return false, nil
+	}
+	_, err = c.Verify(keyring)
+	if err != nil {
+		g.logger.Debug(fmt.Sprintf("commit signature unverifiable: %s (%s)", hash, err.Error()))
+		return false, nil
+	}
+	return true, nil
+}
+
+// Lists the paths of all files changed by the commit with the provided hash.
+// Changes are computed against the commit's first parent.
+// For root commits, all files within the commit are considered changed.
+func (g Git) GetChangedFiles(hash string) ([]string, error) {
+	c, err := g.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return []string{}, err
+	}
+	t, err := c.Tree()
+	if err != nil {
+		return []string{}, err
+	}
+	// use parent tree if commit has parents (otherwise, diff against empty tree)
+	var pt *object.Tree
+	if c.NumParents() > 0 {
+		p, err := c.Parent(0)
+		if err != nil {
+			return []string{}, err
+		}
+		pt, err = p.Tree()
+		if err != nil {
+			return []string{}, err
+		}
+	}
+	chs, err := object.DiffTree(pt, t)
+	if err != nil {
+		return []string{}, err
+	}
+	// collect paths from both sides of each change (to account for deletions and renames)
+	fs := []string{}
+	for _, ch := range chs {
+		if ch.From.Name != "" {
+			fs = append(fs, ch.From.Name)
+		}
+		if ch.To.Name != "" && ch.To.Name != ch.From.Name {
+			fs = append(fs, ch.To.Name)
+		}
+	}
+	return fs, nil
+}
+
+// Lists all tags for the provided remote without fetching them.
+// The remote can either be the name of a remote configured for the local working copy or a url.
+func (g Git) ListRemoteTags(remote string) ([]string, error) {
+	r, err := g.repo.Remote(remote)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		// treat remote as url
+		r = git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "anonymous", URLs: []string{remote}})
+	} else if err != nil {
+		return []string{}, err
+	}
+	rs, err := r.List(&git.ListOptions{PeelingOption: git.IgnorePeeled})
+	if err != nil {
+		return []string{}, err
+	}
+	// collect all tag names
+	t := []string{}
+	for _, rr := range rs {
+		if !rr.Name().IsTag() {
+			continue
+		}
+		t = append(t, rr.Name().Short())
+	}
+	return t, nil
+}
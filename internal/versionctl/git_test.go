@@ -1,12 +1,19 @@
 package versionctl
 
 import (
+	"bytes"
 	"os"
+	"path"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/require"
 )
@@ -40,6 +47,73 @@ func (r *TestRepo) createGitCommit(message string) string {
 	return h.String()
 }
 
+// Helper method to create a git commit with the provided message and author
+func (r *TestRepo) createGitCommitWithAuthor(message string, name string, email string) string {
+	r.t.Helper()
+	require := require.New(r.t)
+	wt, err := r.Worktree()
+	require.Nil(err)
+	h, err := wt.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: name, Email: email, When: time.Now()}})
+	require.Nil(err)
+	return h.String()
+}
+
+// Helper method to create a git commit with the provided message that writes the provided files
+func (r *TestRepo) createGitCommitWithFiles(message string, files ...string) string {
+	r.t.Helper()
+	require := require.New(r.t)
+	wt, err := r.Worktree()
+	require.Nil(err)
+	for _, f := range files {
+		fd, err := wt.Filesystem.Create(f)
+		require.Nil(err)
+		_, err = fd.Write([]byte(message))
+		require.Nil(err)
+		err = fd.Close()
+		require.Nil(err)
+		_, err = wt.Add(f)
+		require.Nil(err)
+	}
+	h, err := wt.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: "author", Email: "email", When: time.Now()}})
+	require.Nil(err)
+	return h.String()
+}
+
+// Helper method to create a git commit signed with the provided key
+func (r *TestRepo) createSignedGitCommit(message string, key *openpgp.Entity) string {
+	r.t.Helper()
+	require := require.New(r.t)
+	wt, err := r.Worktree()
+	require.Nil(err)
+	h, err := wt.Commit(message, &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "author", Email: "email", When: time.Now()}, SignKey: key})
+	require.Nil(err)
+	return h.String()
+}
+
+// Helper method to create a PGP key and its armored public keyring
+func createPGPKey(t testing.TB) (*openpgp.Entity, string) {
+	t.Helper()
+	require := require.New(t)
+	e, err := openpgp.NewEntity("author", "", "email", nil)
+	require.Nil(err)
+	b := &bytes.Buffer{}
+	w, err := armor.Encode(b, openpgp.PublicKeyType, nil)
+	require.Nil(err)
+	err = e.Serialize(w)
+	require.Nil(err)
+	err = w.Close()
+	require.Nil(err)
+	return e, b.String()
+}
+
+// Helper method to mark the provided commit as the repository's shallow boundary
+func (r *TestRepo) makeShallow(hash string) {
+	r.t.Helper()
+	require := require.New(r.t)
+	err := r.Storer.SetShallow([]plumbing.Hash{plumbing.NewHash(hash)})
+	require.Nil(err)
+}
+
 // Helper method to checkout a git branch (creates a branch if it does not exist)
 func (r *TestRepo) checkoutGitBranch(name string) {
 	r.t.Helper()
@@ -52,6 +126,87 @@ func (r *TestRepo) checkoutGitBranch(name string) {
 	require.Nil(err)
 }
 
+// Helper method to create an annotated git tag at the current head.
+func (r *TestRepo) createAnnotatedGitTag(name string) {
+	r.t.Helper()
+	require := require.New(r.t)
+	h, err := r.Head()
+	require.Nil(err)
+	_, err = r.CreateTag(name, h.Hash(), &git.CreateTagOptions{Message: name, Tagger: &object.Signature{Name: "author", Email: "email", When: time.Now()}})
+	require.Nil(err)
+}
+
+// Helper method to create a git tag pointing to a blob (rather than a commit).
+func (r *TestRepo) createBlobGitTag(name string) {
+	r.t.Helper()
+	require := require.New(r.t)
+	b := r.Storer.NewEncodedObject()
+	b.SetType(plumbing.BlobObject)
+	w, err := b.Writer()
+	require.Nil(err)
+	_, err = w.Write([]byte(name))
+	require.Nil(err)
+	err = w.Close()
+	require.Nil(err)
+	bh, err := r.Storer.SetEncodedObject(b)
+	require.Nil(err)
+	err = r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), bh))
+	require.Nil(err)
+}
+
+// Helper method to attach a note to the commit with the provided hash (replaces all existing notes).
+func (r *TestRepo) createGitNote(hash string, note string) {
+	r.t.Helper()
+	r.createGitNoteAtPath(hash, note)
+}
+
+// Helper method to attach a note at the provided notes tree path (e.g., a fanned out 'ab/cdef...') (replaces all existing notes).
+func (r *TestRepo) createGitNoteAtPath(p string, note string) {
+	r.t.Helper()
+	require := require.New(r.t)
+	s := r.Storer
+	b := s.NewEncodedObject()
+	b.SetType(plumbing.BlobObject)
+	w, err := b.Writer()
+	require.Nil(err)
+	_, err = w.Write([]byte(note))
+	require.Nil(err)
+	err = w.Close()
+	require.Nil(err)
+	th, err := s.SetEncodedObject(b)
+	require.Nil(err)
+	// build trees from the innermost path component outwards
+	ps := strings.Split(p, "/")
+	m := filemode.Regular
+	for i := len(ps) - 1; i >= 0; i-- {
+		t := &object.Tree{Entries: []object.TreeEntry{{Name: ps[i], Mode: m, Hash: th}}}
+		to := s.NewEncodedObject()
+		err = t.Encode(to)
+		require.Nil(err)
+		th, err = s.SetEncodedObject(to)
+		require.Nil(err)
+		m = filemode.Dir
+	}
+	sig := object.Signature{Name: "author", Email: "email", When: time.Now()}
+	c := &object.Commit{Author: sig, Committer: sig, Message: "notes", TreeHash: th}
+	co := s.NewEncodedObject()
+	err = c.Encode(co)
+	require.Nil(err)
+	ch, err := s.SetEncodedObject(co)
+	require.Nil(err)
+	err = s.SetReference(plumbing.NewHashReference(plumbing.ReferenceName("refs/notes/commits"), ch))
+	require.Nil(err)
+}
+
+// Helper method to point the notes ref ('refs/notes/commits') at a missing object (e.g., after a partial fetch).
+func (r *TestRepo) createBrokenGitNotes() {
+	r.t.Helper()
+	require := require.New(r.t)
+	h := plumbing.NewHash("0123456789012345678901234567890123456789")
+	err := r.Storer.SetReference(plumbing.NewHashReference(plumbing.ReferenceName("refs/notes/commits"), h))
+	require.Nil(err)
+}
+
 // Helper method to create a git tag at the current head.
 func (r *TestRepo) createGitTag(name string) {
 	r.t.Helper()
@@ -118,6 +273,41 @@ func TestGetCurrentBranch(t *testing.T) {
 	})
 }
 
+func TestGetHash(t *testing.T) {
+	t.Run("resolves revision", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("initial")
+		r.createGitTag("v1.0.0")
+		r.createGitCommit("second")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		gh, err := g.GetHash("v1.0.0")
+
+		require.Nil(err)
+		require.Equal(h, gh)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("initial")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		_, err = g.GetHash("missing")
+
+		require.NotNil(err)
+	})
+}
+
 func TestIterCommits(t *testing.T) {
 	t.Run("captures hash", func(t *testing.T) {
 		require := require.New(t)
@@ -159,6 +349,53 @@ func TestIterCommits(t *testing.T) {
 		require.Equal("message", commits[0].Message)
 	})
 
+	t.Run("captures author and date", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		wt, err := r.Worktree()
+		require.Nil(err)
+		w := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		_, err = wt.Commit("message", &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "name", Email: "name@example.com", When: w}})
+		require.Nil(err)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		commits := []GitCommit{}
+		g.IterCommits("", func(c GitCommit) error {
+			commits = append(commits, c)
+			return nil
+		})
+
+		require.Equal(1, len(commits))
+		require.Equal("name", commits[0].AuthorName)
+		require.Equal("name@example.com", commits[0].AuthorEmail)
+		require.True(w.Equal(commits[0].When))
+	})
+
+	t.Run("ignores broken notes", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("message")
+		r.createBrokenGitNotes()
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		commits := []GitCommit{}
+		err = g.IterCommits("", func(c GitCommit) error {
+			commits = append(commits, c)
+			return nil
+		})
+
+		require.Nil(err)
+		require.Equal(1, len(commits))
+	})
+
 	t.Run("captures tags", func(t *testing.T) {
 		require := require.New(t)
 		d, r := createGitRepo(t)
@@ -185,6 +422,50 @@ func TestIterCommits(t *testing.T) {
 		require.Equal(0, len(commits[1].Tags))
 	})
 
+	t.Run("captures annotated tags", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("tags")
+		r.createAnnotatedGitTag("tag")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		commits := []GitCommit{}
+		g.IterCommits("", func(c GitCommit) error {
+			commits = append(commits, c)
+			return nil
+		})
+
+		require.Equal(1, len(commits))
+		require.Equal([]string{"tag"}, commits[0].Tags)
+	})
+
+	t.Run("ignores non-commit tags", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("tags")
+		r.createGitTag("tag")
+		r.createBlobGitTag("blob")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		commits := []GitCommit{}
+		err = g.IterCommits("", func(c GitCommit) error {
+			commits = append(commits, c)
+			return nil
+		})
+
+		require.Nil(err)
+		require.Equal(1, len(commits))
+		require.Equal([]string{"tag"}, commits[0].Tags)
+	})
+
 	t.Run("iterates in descending order", func(t *testing.T) {
 		require := require.New(t)
 		d, r := createGitRepo(t)
@@ -207,6 +488,33 @@ func TestIterCommits(t *testing.T) {
 		require.Equal("a", commits[1].Message)
 	})
 
+	t.Run("stops at shallow boundary", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("a")
+		r.makeShallow(r.createGitCommit("b"))
+		r.createGitCommit("c")
+		r.createGitCommit("d")
+		// remove history beyond shallow boundary
+		err := os.Remove(path.Join(d, ".git", "objects", h[:2], h[2:]))
+		require.Nil(err)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		commits := []GitCommit{}
+		err = g.IterCommits("", func(c GitCommit) error {
+			commits = append(commits, c)
+			return nil
+		})
+
+		require.Nil(err)
+		require.Equal(3, len(commits))
+		require.Equal("b", commits[2].Message)
+	})
+
 	t.Run("stop iteration", func(t *testing.T) {
 		require := require.New(t)
 		d, r := createGitRepo(t)
@@ -228,6 +536,114 @@ func TestIterCommits(t *testing.T) {
 		require.Equal("b", commits[0].Message)
 	})
 }
+
+func TestGetNote(t *testing.T) {
+	t.Run("gets note", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("message")
+		oh := r.createGitCommit("other")
+		r.createGitNote(h, "note\n")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		n, err := g.GetNote(h)
+		require.Nil(err)
+		on, err := g.GetNote(oh)
+		require.Nil(err)
+
+		require.Equal("note\n", n)
+		require.Equal("", on)
+	})
+
+	t.Run("gets fanned out note", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("message")
+		r.createGitNoteAtPath(h[:2]+"/"+h[2:4]+"/"+h[4:], "note")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		n, err := g.GetNote(h)
+
+		require.Nil(err)
+		require.Equal("note", n)
+	})
+
+	t.Run("no notes", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("message")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		n, err := g.GetNote(h)
+
+		require.Nil(err)
+		require.Equal("", n)
+	})
+
+	t.Run("broken notes", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("message")
+		r.createBrokenGitNotes()
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		_, err = g.GetNote(h)
+
+		require.NotNil(err)
+	})
+}
+
+func TestIsShallow(t *testing.T) {
+	t.Run("not shallow", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("a")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		s, err := g.IsShallow()
+
+		require.Nil(err)
+		require.False(s)
+	})
+
+	t.Run("shallow", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommit("a")
+		r.makeShallow(h)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		s, err := g.IsShallow()
+
+		require.Nil(err)
+		require.True(s)
+	})
+}
+
 func TestListTags(t *testing.T) {
 	t.Run("list tags", func(t *testing.T) {
 		require := require.New(t)
@@ -250,4 +666,203 @@ func TestListTags(t *testing.T) {
 		require.Equal(1, len(ts))
 		require.Equal("test", ts[0])
 	})
+
+	t.Run("ignores non-commit tags", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createAnnotatedGitTag("annotated")
+		r.createBlobGitTag("blob")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		ts, err := g.ListTags()
+
+		require.Nil(err)
+		require.Equal([]string{"annotated"}, ts)
+	})
+}
+
+func TestListRemoteTags(t *testing.T) {
+	t.Run("lists remote tags by url", func(t *testing.T) {
+		require := require.New(t)
+		rd, rr := createGitRepo(t)
+		rr.createGitCommit("initial")
+		rr.createGitTag("v1.0.0")
+		d, r := createGitRepo(t)
+		r.createGitCommit("initial")
+		r.createGitTag("local")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		ts, err := g.ListRemoteTags(rd)
+
+		require.Nil(err)
+		require.Equal([]string{"v1.0.0"}, ts)
+	})
+
+	t.Run("lists remote tags by name", func(t *testing.T) {
+		require := require.New(t)
+		rd, rr := createGitRepo(t)
+		rr.createGitCommit("initial")
+		rr.createGitTag("v1.0.0")
+		d, r := createGitRepo(t)
+		r.createGitCommit("initial")
+		_, err := r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{rd}})
+		require.Nil(err)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		ts, err := g.ListRemoteTags("origin")
+
+		require.Nil(err)
+		require.Equal([]string{"v1.0.0"}, ts)
+	})
+}
+
+func TestVerifyCommitSignature(t *testing.T) {
+	t.Run("signed commit", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		e, kr := createPGPKey(t)
+		h := r.createSignedGitCommit("signed", e)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		ok, err := g.VerifyCommitSignature(h, kr)
+
+		require.Nil(err)
+		require.True(ok)
+	})
+
+	t.Run("unsigned commit", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		_, kr := createPGPKey(t)
+		h := r.createGitCommit("unsigned")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		ok, err := g.VerifyCommitSignature(h, kr)
+
+		require.Nil(err)
+		require.False(ok)
+	})
+
+	t.Run("commit signed by unknown key", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		e, _ := createPGPKey(t)
+		_, kr := createPGPKey(t)
+		h := r.createSignedGitCommit("signed", e)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		ok, err := g.VerifyCommitSignature(h, kr)
+
+		require.Nil(err)
+		require.False(ok)
+	})
+
+	t.Run("invalid keyring", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		e, _ := createPGPKey(t)
+		h := r.createSignedGitCommit("signed", e)
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		_, err = g.VerifyCommitSignature(h, "not a keyring")
+
+		require.ErrorContains(err, "invalid keyring")
+	})
+
+	t.Run("unknown commit", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		_, kr := createPGPKey(t)
+		r.createGitCommit("initial")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		_, err = g.VerifyCommitSignature("0000000000000000000000000000000000000000", kr)
+
+		require.NotNil(err)
+	})
+}
+
+func TestGetChangedFiles(t *testing.T) {
+	t.Run("root commit", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		h := r.createGitCommitWithFiles("initial", "a.txt", "b/c.txt")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		fs, err := g.GetChangedFiles(h)
+
+		require.Nil(err)
+		require.ElementsMatch([]string{"a.txt", "b/c.txt"}, fs)
+	})
+
+	t.Run("child commit", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommitWithFiles("initial", "a.txt", "b/c.txt")
+		h := r.createGitCommitWithFiles("child", "b/c.txt")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		fs, err := g.GetChangedFiles(h)
+
+		require.Nil(err)
+		require.Equal([]string{"b/c.txt"}, fs)
+	})
+
+	t.Run("empty commit", func(t *testing.T) {
+		require := require.New(t)
+		d, r := createGitRepo(t)
+		r.createGitCommitWithFiles("initial", "a.txt")
+		h := r.createGitCommit("empty")
+
+		g, err := NewGit(&GitOpts{
+			Path: d,
+		})
+		require.Nil(err)
+
+		fs, err := g.GetChangedFiles(h)
+
+		require.Nil(err)
+		require.Equal(0, len(fs))
+	})
 }
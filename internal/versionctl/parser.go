@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"regexp"
 	"strings"
 )
 
@@ -16,14 +17,32 @@ type Parser interface {
 // Default options accepted by all parser implementations
 type ParserOpts struct {
 	BreakingChangeTags []string // tags in the commit body that will result in a 'major' version bump
+	ChainMode          string   // (chain) how sub-parser results are combined - 'max' (default) or 'first'
 	Logger             *slog.Logger
+	MatchType          bool              // when true, tags (given without a colon) match the type of a 'type(scope): subject' header
+	Parsers            []ParserSpec      // (chain) ordered list of sub-parsers
+	SkipMarkers        []string          // markers anywhere in the commit message that force a 'none' version change
 	Tags               map[string]string // tags in the commit header that map to version bump values
 }
 
+// A ParserSpec describes a sub-parser of a 'chain' parser
+type ParserSpec struct {
+	BreakingChangeTags []string          `json:"breakingChangeTags"`
+	MatchType          bool              `json:"matchType"`
+	Parser             string            `json:"parser"`
+	SkipMarkers        []string          `json:"skipMarkers"`
+	Tags               map[string]string `json:"tags"`
+}
+
+// Matches the type of a 'type(scope): subject' header (the scope and a breaking change '!' are optional)
+var headerTypeRegex = regexp.MustCompile("^([^\\s():!]+)(?:\\([^)]*\\))?!?:")
+
 // A 'default' parser
 type defaultParser struct {
 	breakingChangeTags []string
 	logger             *slog.Logger
+	matchType          bool
+	skipMarkers        []string
 	tags               map[string]string
 }
 
@@ -42,8 +61,41 @@ func NewParser(k string, o *ParserOpts) (Parser, error) {
 		return &defaultParser{
 			breakingChangeTags: o.BreakingChangeTags,
 			logger:             l,
+			matchType:          o.MatchType,
+			skipMarkers:        o.SkipMarkers,
 			tags:               o.Tags,
 		}, nil
+	case "chain":
+		m := o.ChainMode
+		if m == "" {
+			m = "max"
+		}
+		if m != "max" && m != "first" {
+			return nil, fmt.Errorf("invalid chain mode %s", m)
+		}
+		ps := []Parser{}
+		for _, s := range o.Parsers {
+			if s.Parser == "chain" {
+				return nil, fmt.Errorf("chain parser cannot contain chain parser")
+			}
+			p, err := NewParser(s.Parser, &ParserOpts{
+				BreakingChangeTags: s.BreakingChangeTags,
+				Logger:             l,
+				MatchType:          s.MatchType,
+				SkipMarkers:        s.SkipMarkers,
+				Tags:               s.Tags,
+			})
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, p)
+		}
+		return &chainParser{
+			logger:      l,
+			mode:        m,
+			parsers:     ps,
+			skipMarkers: o.SkipMarkers,
+		}, nil
 	default:
 		return nil, fmt.Errorf("invalid parser type %s", k)
 	}
@@ -51,19 +103,31 @@ func NewParser(k string, o *ParserOpts) (Parser, error) {
 
 // Parses the given message.  Expects the commit message to contain at least one line (a 'header') and optional, additional lines (a 'body').
 // Expects the header to start with a tag specified in [defaultParser.tags].
+// If [defaultParser.matchType] is set, instead expects the type of a 'type(scope): subject' header to equal a tag specified in [defaultParser.tags].
 // If neither expectaions are met, returns a 'none' version change.
 // If a line from the body starts with a tag specified in [defaultParser.breakingChangeTags] - will return a major version change.
+// If the message contains a marker specified in [defaultParser.skipMarkers], returns a 'none' version change (this takes precedence over all tags).
 func (p defaultParser) Parse(message string) VersionChange {
+	if hasSkipMarker(message, p.skipMarkers) {
+		return VersionChange{Value: "none"}
+	}
 	ls := strings.Split(message, "\n")
 
 	h := ls[0]
 	v := ""
-	for t, tv := range p.tags {
-		if !strings.HasPrefix(h, t) {
-			continue
+	if p.matchType {
+		m := headerTypeRegex.FindStringSubmatch(h)
+		if m != nil {
+			v = p.tags[m[1]]
+		}
+	} else {
+		for t, tv := range p.tags {
+			if !strings.HasPrefix(h, t) {
+				continue
+			}
+			v = tv
+			break
 		}
-		v = tv
-		break
 	}
 	if v == "" {
 		return VersionChange{Value: "none"}
@@ -87,3 +151,42 @@ func (p defaultParser) Parse(message string) VersionChange {
 	}
 	return VersionChange{Value: v}
 }
+
+// A 'chain' parser - delegates to an ordered list of sub-parsers
+type chainParser struct {
+	logger      *slog.Logger
+	mode        string
+	parsers     []Parser
+	skipMarkers []string
+}
+
+// Parses the given message with each sub-parser.
+// In 'max' mode, returns the largest version change produced by any sub-parser.
+// In 'first' mode, returns the first version change that isn't 'none'.
+// If the message contains a marker specified in [chainParser.skipMarkers], returns a 'none' version change.
+func (p chainParser) Parse(message string) VersionChange {
+	vc := VersionChange{Value: "none"}
+	if hasSkipMarker(message, p.skipMarkers) {
+		return vc
+	}
+	for _, sp := range p.parsers {
+		c := sp.Parse(message)
+		if p.mode == "first" && c.Value != "none" {
+			return c
+		}
+		if c.Compare(vc) > 0 {
+			vc = c
+		}
+	}
+	return vc
+}
+
+// Returns true if the message contains any of the provided skip markers
+func hasSkipMarker(message string, sms []string) bool {
+	for _, sm := range sms {
+		if strings.Contains(message, sm) {
+			return true
+		}
+	}
+	return false
+}
@@ -45,4 +45,163 @@ func TestDefaultParser(t *testing.T) {
 
 		require.Equal("major", vc.Value)
 	})
+
+	t.Run("type match", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("default", &ParserOpts{
+			MatchType: true,
+			Tags: map[string]string{
+				"fix": "patch",
+			},
+		})
+		require.Nil(err)
+
+		require.Equal("patch", p.Parse("fix: x").Value)
+		require.Equal("patch", p.Parse("fix(api): x").Value)
+		require.Equal("patch", p.Parse("fix!: x").Value)
+		require.Equal("none", p.Parse("fixes: x").Value)
+		require.Equal("none", p.Parse("fix x").Value)
+	})
+
+	t.Run("type match breaking change", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("default", &ParserOpts{
+			BreakingChangeTags: []string{"bct:"},
+			MatchType:          true,
+			Tags: map[string]string{
+				"fix": "patch",
+			},
+		})
+		require.Nil(err)
+
+		vc := p.Parse("fix(api): x\nbct: other")
+
+		require.Equal("major", vc.Value)
+	})
+
+	t.Run("prefix match ignores scope by default", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("default", &ParserOpts{
+			Tags: map[string]string{
+				"fix:": "patch",
+			},
+		})
+		require.Nil(err)
+
+		require.Equal("patch", p.Parse("fix: x").Value)
+		require.Equal("none", p.Parse("fix(api): x").Value)
+	})
+
+	t.Run("skip marker match", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("default", &ParserOpts{
+			SkipMarkers: []string{"[skip release]"},
+			Tags: map[string]string{
+				"feat:": "minor",
+			},
+		})
+		require.Nil(err)
+
+		require.Equal("minor", p.Parse("feat: x").Value)
+		require.Equal("none", p.Parse("feat: x\n\n[skip release]").Value)
+	})
+
+	t.Run("skip marker wins over breaking change", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("default", &ParserOpts{
+			BreakingChangeTags: []string{"bct:"},
+			SkipMarkers:        []string{"[skip release]"},
+			Tags: map[string]string{
+				"tag:": "minor",
+			},
+		})
+		require.Nil(err)
+
+		vc := p.Parse("tag: test [skip release]\nbct: other")
+
+		require.Equal("none", vc.Value)
+	})
+}
+
+func TestChainParser(t *testing.T) {
+	createChainParser := func(t *testing.T, m string) Parser {
+		t.Helper()
+		p, err := NewParser("chain", &ParserOpts{
+			ChainMode: m,
+			Parsers: []ParserSpec{
+				{Tags: map[string]string{"major:": "major", "fix:": "patch"}},
+				{BreakingChangeTags: []string{"BREAKING CHANGE:"}, Tags: map[string]string{"feat:": "minor", "fix:": "patch"}},
+			},
+		})
+		require.Nil(t, err)
+		return p
+	}
+
+	t.Run("no parser match", func(t *testing.T) {
+		require := require.New(t)
+		p := createChainParser(t, "")
+
+		vc := p.Parse("chore: test")
+
+		require.Equal("none", vc.Value)
+	})
+
+	t.Run("second parser match", func(t *testing.T) {
+		require := require.New(t)
+		p := createChainParser(t, "")
+
+		vc := p.Parse("feat: test")
+
+		require.Equal("minor", vc.Value)
+	})
+
+	t.Run("max mode", func(t *testing.T) {
+		require := require.New(t)
+		p := createChainParser(t, "max")
+
+		vc := p.Parse("fix: test\nBREAKING CHANGE: other")
+
+		require.Equal("major", vc.Value)
+	})
+
+	t.Run("first mode", func(t *testing.T) {
+		require := require.New(t)
+		p := createChainParser(t, "first")
+
+		vc := p.Parse("fix: test\nBREAKING CHANGE: other")
+
+		require.Equal("patch", vc.Value)
+	})
+
+	t.Run("skip marker match", func(t *testing.T) {
+		require := require.New(t)
+		p, err := NewParser("chain", &ParserOpts{
+			Parsers: []ParserSpec{
+				{Tags: map[string]string{"fix:": "patch"}},
+				{Tags: map[string]string{"feat:": "minor"}},
+			},
+			SkipMarkers: []string{"[skip release]"},
+		})
+		require.Nil(err)
+
+		vc := p.Parse("feat: x\n\n[skip release]")
+
+		require.Equal("none", vc.Value)
+	})
+
+	t.Run("fails on invalid chain mode", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewParser("chain", &ParserOpts{ChainMode: "min"})
+
+		require.ErrorContains(err, "invalid chain mode")
+	})
+
+	t.Run("fails on invalid sub-parser", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewParser("chain", &ParserOpts{Parsers: []ParserSpec{{Parser: "other"}}})
+
+		require.ErrorContains(err, "invalid parser type")
+	})
 }
@@ -3,8 +3,11 @@ package versionctl
 import (
 	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -17,6 +20,7 @@ import (
 type VersionChange struct {
 	Value           string
 	PrereleaseToken string
+	PrereleaseStart *int // prerelease count used when the prerelease token changes (defaults to 1)
 }
 
 // Returns an 'int' value of a version change struct - useful during comparisons
@@ -50,30 +54,112 @@ type Prerelease struct {
 }
 
 // A Version contains all the components that comprise a semantic version
+// Revision uses 0 (rather than -1) for 'unused' to keep zero-value versions valid - as a result, '1.2.3.0' is indistinguishable from '1.2.3'.
 type Version struct {
 	Major      int
 	Minor      int
 	Patch      int
+	Revision   int // (dotnet) the fourth version component - unused (and omitted by semver formats) when 0
 	Prerelease Prerelease
 	Metadata   string
 }
 
-var versionRegex = regexp.MustCompile(
-	"(?P<major>\\d+)" +
-		"\\.(?P<minor>\\d+)" +
-		"\\.(?P<patch>\\d+)" +
-		"(?:-(?P<prereleaseToken>.+)\\.(?P<prereleaseCount>\\d+))?" +
-		"(?:\\+(?P<metadata>.+))?")
+// Numeric components may not contain leading zeros (e.g., '01')
+var versionPattern = "(?P<major>0|[1-9]\\d*)" +
+	"\\.(?P<minor>0|[1-9]\\d*)" +
+	"\\.(?P<patch>0|[1-9]\\d*)" +
+	"(?:-(?P<prereleaseToken>[0-9A-Za-z.-]+)\\.(?P<prereleaseCount>0|[1-9]\\d*))?" +
+	"(?:\\+(?P<metadata>[0-9A-Za-z.-]+))?"
+
+// Matches an entire semantic version string
+var versionRegex = regexp.MustCompile("^" + versionPattern + "$")
 
-// Creates a [Version] from a given semantic version string
+// Matches semantic versions embedded within a larger string.
+// Like [versionPattern], prerelease tokens and metadata are restricted to semver identifier characters
+// (otherwise, they'd run into the surrounding text - e.g., 'v1.2.3-rc.1 on 2024.01.02').
+// Unlike [versionPattern], leading zeros are tolerated (e.g., dates).
+var versionSearchRegex = regexp.MustCompile("(?P<major>\\d+)" +
+	"\\.(?P<minor>\\d+)" +
+	"\\.(?P<patch>\\d+)" +
+	"(?:-(?P<prereleaseToken>[0-9A-Za-z.-]+)\\.(?P<prereleaseCount>\\d+))?" +
+	"(?:\\+(?P<metadata>[0-9A-Za-z.-]+))?")
+
+// Creates a [Version] from a given semantic version string.
+// Returns an error if the string contains anything other than a semantic version.
+// Use [ExtractVersion] to find a version embedded within a larger string.
 func NewVersion(v string) (Version, error) {
 	m := versionRegex.FindStringSubmatch(v)
 	if m == nil {
 		return Version{}, fmt.Errorf("invalid version string %s", v)
 	}
+	return newVersionFromMatch(versionRegex, m)
+}
+
+// Matches an entire dotnet-style version string (e.g., '1.2.3.4')
+var dotnetVersionRegex = regexp.MustCompile("^(?P<major>\\d+)\\.(?P<minor>\\d+)\\.(?P<patch>\\d+)(?:\\.(?P<revision>\\d+))?$")
+
+// Creates a [Version] from a given dotnet-style version string (Major.Minor.Build[.Revision]).
+// Returns an error if the string contains anything other than a dotnet-style version.
+func NewDotnetVersion(v string) (Version, error) {
+	m := dotnetVersionRegex.FindStringSubmatch(v)
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid dotnet version string %s", v)
+	}
+	nv := Version{}
+	for i, c := range []*int{&nv.Major, &nv.Minor, &nv.Patch, &nv.Revision} {
+		if m[i+1] == "" {
+			continue
+		}
+		cv, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version component %w", err)
+		}
+		*c = cv
+	}
+	return nv, nil
+}
+
+// Searches a string (e.g., 'git describe' output) for the best embedded [Version].
+// Candidates prefixed with 'v' (e.g., v1.2.3) are preferred over bare candidates (e.g., dates).
+// Otherwise, the first parseable candidate is returned.
+// Returns an error if no version is found.
+func ExtractVersion(s string) (Version, error) {
+	var best Version
+	found := false
+	for _, mi := range versionSearchRegex.FindAllStringSubmatchIndex(s, -1) {
+		m := []string{}
+		for i := 0; i < len(mi); i += 2 {
+			if mi[i] == -1 {
+				m = append(m, "")
+				continue
+			}
+			m = append(m, s[mi[i]:mi[i+1]])
+		}
+		v, err := newVersionFromMatch(versionSearchRegex, m)
+		if err != nil {
+			// ignore unparseable candidates
+			continue
+		}
+		if mi[0] > 0 && s[mi[0]-1] == 'v' {
+			// 'v' prefixed candidate is best
+			return v, nil
+		}
+		if !found {
+			best = v
+			found = true
+		}
+	}
+	if !found {
+		return Version{}, fmt.Errorf("no version found in %s", s)
+	}
+	return best, nil
+}
 
+// Creates a [Version] from the submatches of a version regex match.
+// Expects the regex to share the capture group names of [versionPattern].
+func newVersionFromMatch(re *regexp.Regexp, m []string) (Version, error) {
 	extractStr := func(n string) (string, error) {
-		i := versionRegex.SubexpIndex(n)
+		i := re.SubexpIndex(n)
 		if i == -1 {
 			return "", fmt.Errorf("capture group %s not found", n)
 		}
@@ -85,11 +171,11 @@ func NewVersion(v string) (Version, error) {
 		if err != nil {
 			return -1, err
 		}
-		v, err := strconv.ParseInt(vs, 0, 0)
+		v, err := strconv.Atoi(vs)
 		if err != nil {
 			return -1, fmt.Errorf("invalid %s component %w", n, err)
 		}
-		return int(v), nil
+		return v, nil
 	}
 
 	ma, err := extractInt("major")
@@ -125,7 +211,7 @@ func NewVersion(v string) (Version, error) {
 }
 
 // Bumps the current [Version] by the amount specified via the [VersionChange] and returns a new [Version].
-// If the [VersionChange] is a 'prerelease' change and the prerelease token does not match that of the current [Version], the prerelease token is changed and the prerelease count is reset.
+// If the [VersionChange] is a 'prerelease' change and the prerelease token does not match that of the current [Version], the prerelease token is changed and the prerelease count is reset to [VersionChange.PrereleaseStart].
 func (v Version) Bump(c VersionChange) Version {
 	// initialize new version with *only* release components
 	// (metadata is always cleared)
@@ -145,10 +231,15 @@ func (v Version) Bump(c VersionChange) Version {
 		nv.Prerelease = Prerelease{Token: v.Prerelease.Token, Count: v.Prerelease.Count}
 		if nv.Prerelease.Token != c.PrereleaseToken {
 			// reset count if token doesn't match
+			ps := 1
+			if c.PrereleaseStart != nil {
+				ps = *c.PrereleaseStart
+			}
 			nv.Prerelease.Token = c.PrereleaseToken
-			nv.Prerelease.Count = 0
+			nv.Prerelease.Count = ps
+		} else {
+			nv.Prerelease.Count += 1
 		}
-		nv.Prerelease.Count += 1
 	}
 	return nv
 }
@@ -157,24 +248,65 @@ func (v Version) Bump(c VersionChange) Version {
 // Returns < 0 if the current [Version] is less than the other [Version].
 // Return 0 if the current [Version] is equal to the other [Version].
 // Returns > 0 if the current [Version] is greater than the other [Version].
+// Revision compared after patch (unused revisions are 0)
 // Prerelease considered 'less than' release
+// Prereleases are ordered by token, then by count
 // Ignores metadata
 func (l Version) Compare(r Version) int {
-	lvs := []int{l.Major, l.Minor, l.Patch, 0}
+	lvs := []int{l.Major, l.Minor, l.Patch, l.Revision, 0}
 	if l.Prerelease == (Prerelease{}) {
-		lvs[3] = 1
+		lvs[4] = 1
 	}
-	rvs := []int{r.Major, r.Minor, r.Patch, 0}
+	rvs := []int{r.Major, r.Minor, r.Patch, r.Revision, 0}
 	if r.Prerelease == (Prerelease{}) {
-		rvs[3] = 1
+		rvs[4] = 1
 	}
-	for i := 0; i < 4; i++ {
+	for i := 0; i < len(lvs); i++ {
 		d := cmp.Compare(lvs[i], rvs[i])
 		if d != 0 {
 			return d
 		}
 	}
-	return 0
+	d := cmp.Compare(l.Prerelease.Token, r.Prerelease.Token)
+	if d != 0 {
+		return d
+	}
+	return cmp.Compare(l.Prerelease.Count, r.Prerelease.Count)
+}
+
+// Compares the current [Version] with another [Version] (see [Version.Compare]) - breaking ties by metadata.
+// Unlike [Version.Compare], only identical versions are considered equal (useful for deterministic sorting).
+// Versions without metadata are considered 'less than' versions with metadata.
+// Metadata is compared by dot-separated identifier - numeric identifiers are compared numerically.
+func (l Version) CompareStrict(r Version) int {
+	d := l.Compare(r)
+	if d != 0 {
+		return d
+	}
+	if l.Metadata == "" || r.Metadata == "" {
+		return cmp.Compare(len(l.Metadata), len(r.Metadata))
+	}
+	lis := strings.Split(l.Metadata, ".")
+	ris := strings.Split(r.Metadata, ".")
+	for i := 0; i < len(lis) && i < len(ris); i++ {
+		ln, lerr := strconv.Atoi(lis[i])
+		rn, rerr := strconv.Atoi(ris[i])
+		switch {
+		case lerr == nil && rerr == nil:
+			d = cmp.Compare(ln, rn)
+		case lerr == nil:
+			// numeric identifiers are 'less than' alphanumeric identifiers
+			d = -1
+		case rerr == nil:
+			d = 1
+		default:
+			d = cmp.Compare(lis[i], ris[i])
+		}
+		if d != 0 {
+			return d
+		}
+	}
+	return cmp.Compare(len(lis), len(ris))
 }
 
 // Compares the current [Version] with another [Version] and returns the maximal difference between the versions by returning a [VersionChange] object.
@@ -190,91 +322,426 @@ func (l Version) Diff(r Version) VersionChange {
 	return VersionChange{Value: v}
 }
 
+// Returns a single component of [Version] as a string.
+// Accepts 'major' | 'minor' | 'patch' | 'revision' | 'prerelease.token' | 'prerelease.count' | 'metadata'.
+// Returns an empty string for absent prerelease and metadata components.
+// Returns an error if the component name is invalid.
+func (v Version) Field(n string) (string, error) {
+	switch n {
+	case "major":
+		return strconv.Itoa(v.Major), nil
+	case "minor":
+		return strconv.Itoa(v.Minor), nil
+	case "patch":
+		return strconv.Itoa(v.Patch), nil
+	case "revision":
+		return strconv.Itoa(v.Revision), nil
+	case "prerelease.token":
+		return v.Prerelease.Token, nil
+	case "prerelease.count":
+		if v.Prerelease == (Prerelease{}) {
+			return "", nil
+		}
+		return strconv.Itoa(v.Prerelease.Count), nil
+	case "metadata":
+		return v.Metadata, nil
+	default:
+		return "", fmt.Errorf("invalid field %s", n)
+	}
+}
+
 // Returns a 'release' [Version] (i.e., prerelease and metadata components removed) from the current [Version].
 func (v Version) Release() Version {
-	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Revision: v.Revision}
+}
+
+// A FormatProfile describes a custom string representation of a [Version].
+// Fields are used literally - an empty separator omits the separator entirely (e.g., '1.2.3-rc1').
+type FormatProfile struct {
+	Prefix              string `json:"prefix"`              // prepended to the version
+	PrereleaseSeparator string `json:"prereleaseSeparator"` // separates the release and prerelease components
+	CountSeparator      string `json:"countSeparator"`      // separates the prerelease token and count
+	MetadataSeparator   string `json:"metadataSeparator"`   // separates the metadata component
+}
+
+// The [FormatProfile] describing a semantic version (e.g., '1.2.3-rc.1+metadata')
+var SemverProfile = FormatProfile{PrereleaseSeparator: "-", CountSeparator: ".", MetadataSeparator: "+"}
+
+// Unmarshals a [FormatProfile] from JSON.
+// Fields absent from the JSON document default to those of [SemverProfile].
+func (p *FormatProfile) UnmarshalJSON(b []byte) error {
+	type formatProfile FormatProfile
+	fp := formatProfile(SemverProfile)
+	err := json.Unmarshal(b, &fp)
+	if err != nil {
+		return err
+	}
+	*p = FormatProfile(fp)
+	return nil
+}
+
+// Returns a string representation of [Version] using the provided [FormatProfile].
+func (v Version) Format(p FormatProfile) string {
+	s := fmt.Sprintf("%s%d.%d.%d", p.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Prerelease != (Prerelease{}) {
+		s = fmt.Sprintf("%s%s%s%s%d", s, p.PrereleaseSeparator, v.Prerelease.Token, p.CountSeparator, v.Prerelease.Count)
+	}
+	if v.Metadata != "" {
+		s = fmt.Sprintf("%s%s%s", s, p.MetadataSeparator, v.Metadata)
+	}
+	return s
+}
+
+// Creates a [Version] from a version string formatted with the provided [FormatProfile].
+// Returns an error if the string does not match the profile.
+func ParseVersion(v string, p FormatProfile) (Version, error) {
+	re, err := regexp.Compile("^" + regexp.QuoteMeta(p.Prefix) +
+		"(?P<major>\\d+)" +
+		"\\.(?P<minor>\\d+)" +
+		"\\.(?P<patch>\\d+)" +
+		"(?:" + regexp.QuoteMeta(p.PrereleaseSeparator) + "(?P<prereleaseToken>.+?)" + regexp.QuoteMeta(p.CountSeparator) + "(?P<prereleaseCount>\\d+))?" +
+		"(?:" + regexp.QuoteMeta(p.MetadataSeparator) + "(?P<metadata>.+))?$")
+	if err != nil {
+		return Version{}, err
+	}
+	m := re.FindStringSubmatch(v)
+	if m == nil {
+		return Version{}, fmt.Errorf("invalid version string %s", v)
+	}
+	return newVersionFromMatch(re, m)
+}
+
+// Returns a git tag representation of [Version] - the semver representation with the provided prefix (e.g., 'v', 'foo/v').
+func (v Version) Tag(p string) string {
+	return fmt.Sprintf("%s%s", p, v.String("semver"))
+}
+
+// The default prefix used by the 'git' format (see [Version.String]).
+// Also the default prefix of version tags (see [AnalyzerOpts.TagPrefix]).
+// Use [Analyzer.FormatVersion] to format with a configured prefix.
+var GitTagPrefix = "v"
+
+// The formats supported by [Version.String]
+var Formats = []string{"docker", "dotnet", "git", "node", "semver"}
+
+// Normalizes a format name - trims whitespace and lowercases (e.g., ' Docker ' -> 'docker')
+func NormalizeFormat(f string) string {
+	return strings.ToLower(strings.TrimSpace(f))
 }
 
 // Returns a string representation of [Version].
+// Format names are normalized (see [NormalizeFormat]).
 // Defaults to 'semver' when format not specified, or format unrecognized.
-// docker: semver, replaces '+' with '-'
-// git: adds 'v' prefix to semver
-// node: semver, replaces '+' with '-'
+// docker: semver, replaces '+' with '-' (metadata segments keep their '.' separators - e.g., '1.2.3-a.b')
+// dotnet: four-part version (e.g., '1.2.3.4') - prerelease and metadata omitted
+// git: adds [GitTagPrefix] prefix to semver (see [Version.Tag])
+// node: semver, replaces '+' with '-' (metadata segments keep their '.' separators)
 // semver: semantic version representation
 func (v Version) String(f string) string {
-	switch f {
+	switch NormalizeFormat(f) {
 	case "docker":
 		sv := v.String("semver")
 		s := strings.Replace(sv, "+", "-", -1)
 		return s
+	case "dotnet":
+		return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Revision)
 	case "git":
-		sv := v.String("semver")
-		s := fmt.Sprintf("v%s", sv)
-		return s
+		return v.Tag(GitTagPrefix)
 	case "node":
 		sv := v.String("semver")
 		s := strings.Replace(sv, "+", "-", -1)
 		return s
 	case "semver":
-		s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
-		if v.Prerelease != (Prerelease{}) {
-			s = fmt.Sprintf("%s-%s.%d", s, v.Prerelease.Token, v.Prerelease.Count)
-		}
-		if v.Metadata != "" {
-			s = fmt.Sprintf("%s+%s", s, v.Metadata)
-		}
-		return s
+		return v.Format(SemverProfile)
 	default:
 		return v.String("semver")
 	}
 }
 
-// Writes a version string to a known file.
-// If the file is unrecognized, an error is raised.
-// If any part of the file operation fails, an error is raised.
-func SetVersion(v string, f string) error {
-	s, err := os.Stat(f)
+// A versionFile pairs a file matcher with functions that read and write versions of matching files.
+// Built-in writers also receive the [SetVersionOpts] provided to [SetVersionWithOpts] (e.g., Dockerfile directives).
+// Files without a reader can only be written.
+type versionFile struct {
+	match func(name string) bool
+	read  func(path string) (string, error)
+	write func(path string, version string, o *SetVersionOpts) error
+}
+
+// Registered version files - consulted in registration order (see [RegisterVersionFile])
+var versionFiles = []versionFile{}
+
+// Registers a writer for a file type, making it available to [SetVersion].
+// The matcher receives the file's base name.  When multiple matchers accept a file, the first registered is used.
+// Registered file types can only be written - [GetVersion] fails for them.
+func RegisterVersionFile(match func(name string) bool, write func(path string, version string) error) {
+	versionFiles = append(versionFiles, versionFile{match: match, write: func(p string, v string, _ *SetVersionOpts) error {
+		return write(p, v)
+	}})
+}
+
+func init() {
+	versionFiles = append(versionFiles,
+		versionFile{match: func(n string) bool { return n == "pyproject.toml" }, read: readPyprojectVersion, write: writePyprojectVersion},
+		versionFile{match: func(n string) bool { return n == "package.json" }, read: readPackageJSONVersion, write: writePackageJSONVersion},
+		versionFile{match: func(n string) bool { return n == "Dockerfile" || strings.HasSuffix(n, ".Dockerfile") }, write: writeDockerfileVersion},
+	)
+}
+
+// Finds the registered version file matching a file's base name.
+// Returns an error if the file is unrecognized.
+func findVersionFile(f string) (versionFile, error) {
+	n := filepath.Base(f)
+	for _, vf := range versionFiles {
+		if vf.match(n) {
+			return vf, nil
+		}
+	}
+	return versionFile{}, fmt.Errorf("unknown file %s", f)
+}
+
+// Reads a file - treating a missing file as an empty document
+func readFileOrEmpty(f string) ([]byte, error) {
+	fd, err := os.ReadFile(f)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []byte{}, nil
+	}
+	return fd, err
+}
+
+// Reads the project.version field of a pyproject.toml file
+func readPyprojectVersion(f string) (string, error) {
+	fd, err := os.ReadFile(f)
+	if err != nil {
+		return "", err
+	}
+	d := map[string]any{}
+	err = toml.Unmarshal(fd, &d)
+	if err != nil {
+		return "", err
+	}
+	p, _ := d["project"].(map[string]any)
+	v, _ := p["version"].(string)
+	return v, nil
+}
+
+// Reads the version field of a package.json file
+func readPackageJSONVersion(f string) (string, error) {
+	fd, err := os.ReadFile(f)
+	if err != nil {
+		return "", err
+	}
+	d := map[string]any{}
+	err = json.Unmarshal(fd, &d)
+	if err != nil {
+		return "", err
+	}
+	v, _ := d["version"].(string)
+	return v, nil
+}
+
+// Writes a version string to the project.version field of a pyproject.toml file
+func writePyprojectVersion(f string, v string, _ *SetVersionOpts) error {
+	fd, err := readFileOrEmpty(f)
 	if err != nil {
 		return err
 	}
-	if s.Name() == "pyproject.toml" {
-		fd, err := os.ReadFile(f)
-		if err != nil {
-			return err
+	d := map[string]any{}
+	toml.Unmarshal(fd, &d)
+	_, ok := d["project"]
+	if !ok {
+		d["project"] = map[string]any{}
+	}
+	d["project"].(map[string]any)["version"] = v
+	fd, err = toml.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f, fd, 0o644)
+}
+
+// Writes a version string to the version field of a package.json file
+func writePackageJSONVersion(f string, v string, _ *SetVersionOpts) error {
+	fd, err := readFileOrEmpty(f)
+	if err != nil {
+		return err
+	}
+	d := map[string]any{}
+	json.Unmarshal(fd, &d)
+	d["version"] = v
+	fd, err = json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f, fd, 0o644)
+}
+
+// Splits Dockerfile instruction arguments into whitespace-separated tokens - whitespace within quotes does not split tokens.
+// Returns the [start, end) offsets of each token.
+func splitDockerfileArgs(s string) [][2]int {
+	ts := [][2]int{}
+	st := -1
+	q := byte(0)
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case q == '"' && ch == '\\':
+			// skip escaped character
+			i++
+		case q != 0:
+			if ch == q {
+				q = 0
+			}
+		case ch == ' ' || ch == '\t':
+			if st != -1 {
+				ts = append(ts, [2]int{st, i})
+				st = -1
+			}
+			continue
+		case ch == '"' || ch == '\'':
+			q = ch
 		}
-		d := map[string]any{}
-		toml.Unmarshal(fd, &d)
-		_, ok := d["project"]
-		if !ok {
-			d["project"] = map[string]any{}
+		if st == -1 {
+			st = i
 		}
-		d["project"].(map[string]any)["version"] = v
-		fd, err = toml.Marshal(d)
-		if err != nil {
-			return err
+	}
+	if st != -1 {
+		ts = append(ts, [2]int{st, len(s)})
+	}
+	return ts
+}
+
+// Writes a version string to a key of all matching directives within a Dockerfile (see [SetVersionOpts]).
+// Directive arguments are tokenized as 'key=value' pairs - only exact key matches are replaced (e.g., never text within quoted values).
+// All other lines (and comments) are preserved.  Quoted values remain quoted.
+// Missing files are created with a single directive (e.g., 'ARG VERSION=1.2.3').
+// Returns an error if the directive key isn't found.
+func writeDockerfileVersion(f string, v string, o *SetVersionOpts) error {
+	dd := o.DockerfileDirective
+	if dd == "" {
+		dd = "ARG"
+	}
+	dk := o.DockerfileKey
+	if dk == "" {
+		dk = "VERSION"
+	}
+	fd, err := os.ReadFile(f)
+	if errors.Is(err, fs.ErrNotExist) {
+		return os.WriteFile(f, []byte(fmt.Sprintf("%s %s=%s\n", dd, dk, v)), 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	found := false
+	c := false
+	m := false
+	ls := strings.Split(string(fd), "\n")
+	for i, l := range ls {
+		if strings.HasPrefix(strings.TrimSpace(l), "#") {
+			// comments don't interrupt line continuations
+			continue
 		}
-		err = os.WriteFile(f, fd, 0o644)
-		if err != nil {
-			return err
+		s := 0
+		if !c {
+			// line starts a new instruction
+			// directives are case-insensitive - keys are not
+			s = len(l) - len(strings.TrimLeft(l, " \t"))
+			e := s
+			for e < len(l) && l[e] != ' ' && l[e] != '\t' {
+				e++
+			}
+			m = strings.EqualFold(l[s:e], dd)
+			s = e
 		}
-	} else if s.Name() == "package.json" {
-		fd, err := os.ReadFile(f)
-		if err != nil {
-			return err
+		e := len(strings.TrimRight(l, " \t\r"))
+		// track continuations of every instruction (e.g., a 'RUN' script spanning multiple lines)
+		c = e > s && l[e-1] == '\\'
+		if !m {
+			continue
 		}
-		d := map[string]any{}
-		json.Unmarshal(fd, &d)
-		d["version"] = v
-		fd, err = json.Marshal(d)
-		if err != nil {
-			return err
+		if c {
+			// directive continues on the next line
+			e--
+		}
+		a := l[s:e]
+		ts := splitDockerfileArgs(a)
+		for j := len(ts) - 1; j >= 0; j-- {
+			t := a[ts[j][0]:ts[j][1]]
+			k, cv, _ := strings.Cut(t, "=")
+			if k != dk {
+				continue
+			}
+			nv := v
+			if strings.HasPrefix(cv, `"`) || strings.HasPrefix(cv, "'") {
+				nv = cv[:1] + v + cv[:1]
+			}
+			a = a[:ts[j][0]] + k + "=" + nv + a[ts[j][1]:]
+			found = true
 		}
-		err = os.WriteFile(f, fd, 0o644)
+		ls[i] = l[:s] + a + l[e:]
+	}
+	if !found {
+		return fmt.Errorf("%s %s not found in %s", dd, dk, f)
+	}
+	return os.WriteFile(f, []byte(strings.Join(ls, "\n")), 0o644)
+}
+
+// Options to provide [SetVersionWithOpts]
+type SetVersionOpts struct {
+	AllowInvalid        bool   // when true, version strings aren't validated (e.g., for intentional non-semver values)
+	Create              bool   // when true, missing files are created (writers receive a path that may not exist)
+	DockerfileDirective string // (Dockerfile) the directive holding the version (default: 'ARG') - e.g., 'LABEL'
+	DockerfileKey       string // (Dockerfile) the directive key holding the version (default: 'VERSION') - e.g., 'org.opencontainers.image.version'
+}
+
+// Writes a version string to a known file (see [RegisterVersionFile]).
+// If the version string is invalid (see [NewVersion]), an error is raised.
+// If the file is unrecognized, an error is raised.
+// If any part of the file operation fails, an error is raised.
+func SetVersion(v string, f string) error {
+	return SetVersionWithOpts(v, f, &SetVersionOpts{})
+}
+
+// Writes a version string to a known file (see [SetVersion]) using the provided [SetVersionOpts].
+func SetVersionWithOpts(v string, f string, o *SetVersionOpts) error {
+	if !o.AllowInvalid {
+		_, err := NewVersion(v)
 		if err != nil {
 			return err
 		}
-	} else {
-		return fmt.Errorf("unknown file %s", f)
 	}
-	return nil
+	_, err := os.Stat(f)
+	if err != nil && !(o.Create && errors.Is(err, fs.ErrNotExist)) {
+		return err
+	}
+	vf, err := findVersionFile(f)
+	if err != nil {
+		return err
+	}
+	return vf.write(f, v, o)
+}
+
+// Reads a version string from a known file (see [RegisterVersionFile]).
+// Only pyproject.toml and package.json files are readable - other known files (e.g., Dockerfiles) raise an error.
+// If the file is unrecognized, an error is raised.
+// If the file does not declare a version, an error is raised.
+func GetVersion(f string) (string, error) {
+	_, err := os.Stat(f)
+	if err != nil {
+		return "", err
+	}
+	vf, err := findVersionFile(f)
+	if err != nil {
+		return "", err
+	}
+	if vf.read == nil {
+		return "", fmt.Errorf("reading versions from %s is unsupported", f)
+	}
+	v, err := vf.read(f)
+	if err != nil {
+		return "", err
+	}
+	if v == "" {
+		return "", fmt.Errorf("version not found in %s", f)
+	}
+	return v, nil
 }
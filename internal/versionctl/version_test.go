@@ -111,6 +111,50 @@ func TestVersionBump(t *testing.T) {
 		require.Equal(Prerelease{Token: "abc", Count: 1}, nv.Prerelease)
 		require.Equal("", nv.Metadata)
 	})
+
+	t.Run("prerelease (start 0, token match)", func(t *testing.T) {
+		require := require.New(t)
+		ps := 0
+		v := Version{Prerelease: Prerelease{Token: "rc", Count: 0}}
+		c := VersionChange{Value: "prerelease", PrereleaseToken: "rc", PrereleaseStart: &ps}
+
+		nv := v.Bump(c)
+
+		require.Equal(Prerelease{Token: "rc", Count: 1}, nv.Prerelease)
+	})
+
+	t.Run("prerelease (start 0, token mismatch)", func(t *testing.T) {
+		require := require.New(t)
+		ps := 0
+		v := Version{Prerelease: Prerelease{Token: "rc", Count: 3}}
+		c := VersionChange{Value: "prerelease", PrereleaseToken: "abc", PrereleaseStart: &ps}
+
+		nv := v.Bump(c)
+
+		require.Equal(Prerelease{Token: "abc", Count: 0}, nv.Prerelease)
+	})
+
+	t.Run("prerelease (start 1, token mismatch)", func(t *testing.T) {
+		require := require.New(t)
+		ps := 1
+		v := Version{Prerelease: Prerelease{Token: "rc", Count: 3}}
+		c := VersionChange{Value: "prerelease", PrereleaseToken: "abc", PrereleaseStart: &ps}
+
+		nv := v.Bump(c)
+
+		require.Equal(Prerelease{Token: "abc", Count: 1}, nv.Prerelease)
+	})
+
+	t.Run("prerelease (start 0, from release)", func(t *testing.T) {
+		require := require.New(t)
+		ps := 0
+		v := Version{Major: 1}
+		c := VersionChange{Value: "prerelease", PrereleaseToken: "rc", PrereleaseStart: &ps}
+
+		nv := v.Bump(c)
+
+		require.Equal(Version{Major: 1, Prerelease: Prerelease{Token: "rc", Count: 0}}, nv)
+	})
 }
 
 func TestVersionCompare(t *testing.T) {
@@ -153,6 +197,108 @@ func TestVersionCompare(t *testing.T) {
 
 		require.Less(d, 0)
 	})
+
+	t.Run("prerelease count lt", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Prerelease: Prerelease{Token: "rc", Count: 0}}
+		r := Version{Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		d := l.Compare(r)
+
+		require.Less(d, 0)
+	})
+
+	t.Run("prerelease token lt", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Prerelease: Prerelease{Token: "alpha", Count: 2}}
+		r := Version{Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		d := l.Compare(r)
+
+		require.Less(d, 0)
+	})
+
+	t.Run("revision gt", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Minor: 2, Patch: 3, Revision: 4}
+		r := Version{Major: 1, Minor: 2, Patch: 3}
+
+		d := l.Compare(r)
+
+		require.Greater(d, 0)
+	})
+
+	t.Run("revision lt patch", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Minor: 2, Patch: 3, Revision: 4}
+		r := Version{Major: 1, Minor: 2, Patch: 4}
+
+		d := l.Compare(r)
+
+		require.Less(d, 0)
+	})
+}
+
+func TestVersionCompareStrict(t *testing.T) {
+	t.Run("compares version first", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Metadata: "a"}
+		r := Version{Major: 2}
+
+		d := l.CompareStrict(r)
+
+		require.Less(d, 0)
+	})
+
+	t.Run("eq", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Metadata: "build.1"}
+		r := Version{Major: 1, Metadata: "build.1"}
+
+		d := l.CompareStrict(r)
+
+		require.Equal(0, d)
+	})
+
+	t.Run("no metadata less than metadata", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1}
+		r := Version{Major: 1, Metadata: "build"}
+
+		d := l.CompareStrict(r)
+
+		require.Less(d, 0)
+	})
+
+	t.Run("numeric identifiers compared numerically", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Metadata: "build.2"}
+		r := Version{Major: 1, Metadata: "build.10"}
+
+		d := l.CompareStrict(r)
+
+		require.Less(d, 0)
+	})
+
+	t.Run("numeric identifiers less than alphanumeric identifiers", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Metadata: "1"}
+		r := Version{Major: 1, Metadata: "a"}
+
+		d := l.CompareStrict(r)
+
+		require.Less(d, 0)
+	})
+
+	t.Run("fewer identifiers less than more identifiers", func(t *testing.T) {
+		require := require.New(t)
+		l := Version{Major: 1, Metadata: "build"}
+		r := Version{Major: 1, Metadata: "build.1"}
+
+		d := l.CompareStrict(r)
+
+		require.Less(d, 0)
+	})
 }
 
 func TestVersionDiff(t *testing.T) {
@@ -202,6 +348,53 @@ func TestVersionRelease(t *testing.T) {
 	})
 }
 
+func TestVersionField(t *testing.T) {
+	v := Version{Major: 1, Minor: 2, Patch: 3, Revision: 4, Prerelease: Prerelease{Token: "rc", Count: 5}, Metadata: "metadata"}
+
+	t.Run("release components", func(t *testing.T) {
+		require := require.New(t)
+		for n, e := range map[string]string{"major": "1", "minor": "2", "patch": "3", "revision": "4"} {
+			f, err := v.Field(n)
+			require.Nil(err)
+			require.Equal(e, f)
+		}
+	})
+
+	t.Run("prerelease token", func(t *testing.T) {
+		require := require.New(t)
+		f, err := v.Field("prerelease.token")
+		require.Nil(err)
+		require.Equal("rc", f)
+	})
+
+	t.Run("prerelease count", func(t *testing.T) {
+		require := require.New(t)
+		f, err := v.Field("prerelease.count")
+		require.Nil(err)
+		require.Equal("5", f)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		require := require.New(t)
+		f, err := v.Field("metadata")
+		require.Nil(err)
+		require.Equal("metadata", f)
+	})
+
+	t.Run("absent prerelease", func(t *testing.T) {
+		require := require.New(t)
+		f, err := Version{Major: 1}.Field("prerelease.count")
+		require.Nil(err)
+		require.Equal("", f)
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		require := require.New(t)
+		_, err := v.Field("build")
+		require.ErrorContains(err, "invalid field build")
+	})
+}
+
 func TestVersionString(t *testing.T) {
 	v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "metadata"}
 
@@ -210,11 +403,35 @@ func TestVersionString(t *testing.T) {
 		require.Equal("1.2.3-rc.1-metadata", v.String("docker"))
 	})
 
+	t.Run("docker and node preserve metadata segments", func(t *testing.T) {
+		require := require.New(t)
+		v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "build.5"}
+		require.Equal("1.2.3-rc.1-build.5", v.String("docker"))
+		require.Equal("1.2.3-rc.1-build.5", v.String("node"))
+	})
+
+	t.Run("dotnet", func(t *testing.T) {
+		require := require.New(t)
+		require.Equal("1.2.3.0", v.String("dotnet"))
+		require.Equal("1.2.3.4", Version{Major: 1, Minor: 2, Patch: 3, Revision: 4}.String("dotnet"))
+	})
+
 	t.Run("git", func(t *testing.T) {
 		require := require.New(t)
 		require.Equal("v1.2.3-rc.1+metadata", v.String("git"))
 	})
 
+	t.Run("git with custom prefix", func(t *testing.T) {
+		require := require.New(t)
+		p := GitTagPrefix
+		GitTagPrefix = "release-"
+		t.Cleanup(func() {
+			GitTagPrefix = p
+		})
+
+		require.Equal("release-1.2.3-rc.1+metadata", v.String("git"))
+	})
+
 	t.Run("node", func(t *testing.T) {
 		require := require.New(t)
 		require.Equal("1.2.3-rc.1-metadata", v.String("node"))
@@ -225,6 +442,109 @@ func TestVersionString(t *testing.T) {
 		require.Equal("1.2.3-rc.1+metadata", v.String("semver"))
 		require.Equal("1.2.3-rc.1+metadata", v.String(""))
 	})
+
+	t.Run("normalizes format", func(t *testing.T) {
+		require := require.New(t)
+		v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "metadata"}
+		require.Equal("1.2.3-rc.1-metadata", v.String("Docker"))
+		require.Equal("v1.2.3-rc.1+metadata", v.String(" GIT "))
+	})
+}
+
+func TestVersionFormat(t *testing.T) {
+	v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "metadata"}
+
+	t.Run("semver profile", func(t *testing.T) {
+		require := require.New(t)
+		require.Equal("1.2.3-rc.1+metadata", v.Format(SemverProfile))
+	})
+
+	t.Run("no count separator", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{PrereleaseSeparator: "-", MetadataSeparator: "+"}
+		require.Equal("1.2.3-rc1+metadata", v.Format(p))
+	})
+
+	t.Run("custom prerelease separator", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{Prefix: "v", PrereleaseSeparator: "~", CountSeparator: ".", MetadataSeparator: "+"}
+		require.Equal("v1.2.3~rc.1+metadata", v.Format(p))
+	})
+
+	t.Run("release", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{PrereleaseSeparator: "-", MetadataSeparator: "+"}
+		require.Equal("1.2.3", Version{Major: 1, Minor: 2, Patch: 3}.Format(p))
+	})
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Run("multi-segment metadata round trip", func(t *testing.T) {
+		require := require.New(t)
+		v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "build.5"}
+
+		pv, err := ParseVersion(v.Format(SemverProfile), SemverProfile)
+
+		require.Nil(err)
+		require.Equal(v, pv)
+	})
+
+	t.Run("no count separator round trip", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{PrereleaseSeparator: "-", MetadataSeparator: "+"}
+		v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 12}, Metadata: "metadata"}
+
+		pv, err := ParseVersion(v.Format(p), p)
+
+		require.Nil(err)
+		require.Equal(v, pv)
+	})
+
+	t.Run("custom prerelease separator round trip", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{Prefix: "v", PrereleaseSeparator: "~", CountSeparator: ".", MetadataSeparator: "+"}
+		v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}}
+
+		pv, err := ParseVersion(v.Format(p), p)
+
+		require.Nil(err)
+		require.Equal(v, pv)
+	})
+
+	t.Run("rejects mismatched profile", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{Prefix: "v", PrereleaseSeparator: "~", CountSeparator: ".", MetadataSeparator: "+"}
+
+		_, err := ParseVersion("1.2.3-rc.1", p)
+
+		require.ErrorContains(err, "invalid version string")
+	})
+}
+
+func TestFormatProfileUnmarshalJSON(t *testing.T) {
+	t.Run("defaults to semver profile", func(t *testing.T) {
+		require := require.New(t)
+		p := FormatProfile{}
+
+		err := json.Unmarshal([]byte(`{"countSeparator": ""}`), &p)
+
+		require.Nil(err)
+		require.Equal(FormatProfile{PrereleaseSeparator: "-", CountSeparator: "", MetadataSeparator: "+"}, p)
+	})
+}
+
+func TestVersionTag(t *testing.T) {
+	v := Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "metadata"}
+
+	t.Run("v prefix", func(t *testing.T) {
+		require := require.New(t)
+		require.Equal("v1.2.3-rc.1+metadata", v.Tag("v"))
+	})
+
+	t.Run("path prefix", func(t *testing.T) {
+		require := require.New(t)
+		require.Equal("foo/v1.2.3-rc.1+metadata", v.Tag("foo/v"))
+	})
 }
 
 func TestNewVersion(t *testing.T) {
@@ -236,6 +556,109 @@ func TestNewVersion(t *testing.T) {
 		require.NotNil(err)
 	})
 
+	t.Run("multi-segment metadata", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := NewVersion("1.2.3+a.b.c")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Metadata: "a.b.c"}, v)
+		require.Equal("1.2.3+a.b.c", v.String("semver"))
+	})
+
+	t.Run("multi-segment metadata ending in number", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := NewVersion("1.2.3-rc.1+build.5")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}, Metadata: "build.5"}, v)
+		require.Equal("1.2.3-rc.1+build.5", v.String("semver"))
+	})
+
+	t.Run("multi-segment prerelease token and metadata", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := NewVersion("1.2.3-alpha.beta.1+a.b.2")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "alpha.beta", Count: 1}, Metadata: "a.b.2"}, v)
+		require.Equal("1.2.3-alpha.beta.1+a.b.2", v.String("semver"))
+	})
+
+	t.Run("rejects trailing junk", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("1.2.3 and more")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("rejects leading junk", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("version 1.2.3")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("rejects incomplete prerelease", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("1.2.3-extra garbage")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("rejects leading zeros", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("01.2.3")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("rejects leading zeros in prerelease count", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("1.2.3-rc.01")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("parses components as base 10", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := NewVersion("1.9.0")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 9}, v)
+	})
+
+	t.Run("rejects leading zero that looks octal", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("1.08.0")
+
+		require.ErrorContains(err, "invalid version string 1.08.0")
+	})
+
+	t.Run("rejects invalid prerelease token characters", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("1.2.3-r c.1")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
+	t.Run("rejects invalid metadata characters", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewVersion("1.2.3+a b")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+
 	t.Run("release", func(t *testing.T) {
 		require := require.New(t)
 
@@ -287,12 +710,196 @@ func TestNewVersion(t *testing.T) {
 		require.Equal(Prerelease{Token: "rc", Count: 1}, v.Prerelease)
 		require.Equal("metadata", v.Metadata)
 	})
-}
 
-func TestSetVersion(t *testing.T) {
-	t.Run("sets pyproject.toml", func(t *testing.T) {
+	t.Run("rejects four-part version", func(t *testing.T) {
 		require := require.New(t)
-		d := t.TempDir()
+
+		_, err := NewVersion("1.2.3.4")
+
+		require.ErrorContains(err, "invalid version string")
+	})
+}
+
+func TestNewDotnetVersion(t *testing.T) {
+	t.Run("four-part version", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := NewDotnetVersion("1.2.3.4")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Revision: 4}, v)
+	})
+
+	t.Run("three-part version", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := NewDotnetVersion("1.2.3")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3}, v)
+	})
+
+	t.Run("rejects semver prerelease", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := NewDotnetVersion("1.2.3-rc.1")
+
+		require.ErrorContains(err, "invalid dotnet version string")
+	})
+}
+
+func TestExtractVersion(t *testing.T) {
+	t.Run("git describe output", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ExtractVersion("v1.2.3-4-gabc123")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3}, v)
+	})
+
+	t.Run("prefers 'v' prefixed version", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ExtractVersion("built 2024.01.02 from v1.2.3-rc.1")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("prerelease does not run into surrounding text", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ExtractVersion("deployed v1.2.3-rc.1 on 2024.01.02")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3, Prerelease: Prerelease{Token: "rc", Count: 1}}, v)
+	})
+
+	t.Run("metadata does not run into surrounding text", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ExtractVersion("built 2024.01.02+x from v1.2.3")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3}, v)
+	})
+
+	t.Run("falls back to first version", func(t *testing.T) {
+		require := require.New(t)
+
+		v, err := ExtractVersion("1.2.3-extra garbage")
+
+		require.Nil(err)
+		require.Equal(Version{Major: 1, Minor: 2, Patch: 3}, v)
+	})
+
+	t.Run("fails when no version found", func(t *testing.T) {
+		require := require.New(t)
+
+		_, err := ExtractVersion("abcd")
+
+		require.ErrorContains(err, "no version found")
+	})
+}
+
+func TestGetVersion(t *testing.T) {
+	t.Run("gets pyproject.toml", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "pyproject.toml")
+		err := os.WriteFile(f, []byte("[project]\nversion = \"1.0.0\"\n"), 0o755)
+		require.Nil(err)
+
+		v, err := GetVersion(f)
+
+		require.Nil(err)
+		require.Equal("1.0.0", v)
+	})
+
+	t.Run("gets package.json", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+		err := os.WriteFile(f, []byte(`{"version": "1.0.0"}`), 0o755)
+		require.Nil(err)
+
+		v, err := GetVersion(f)
+
+		require.Nil(err)
+		require.Equal("1.0.0", v)
+	})
+
+	t.Run("fails when version missing", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+		err := os.WriteFile(f, []byte(`{}`), 0o755)
+		require.Nil(err)
+
+		_, err = GetVersion(f)
+
+		require.ErrorContains(err, "version not found")
+	})
+
+	t.Run("fails for missing file", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+
+		err := SetVersion("1.0.0", f)
+
+		require.ErrorIs(err, os.ErrNotExist)
+	})
+
+	t.Run("fails for unknown file type", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "unknown.txt")
+		_, err := os.Create(f)
+		require.Nil(err)
+
+		_, err = GetVersion(f)
+
+		require.ErrorContains(err, "unknown file")
+	})
+
+	t.Run("fails for write-only file type", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("ARG VERSION=1.0.0\n"), 0o755)
+		require.Nil(err)
+
+		_, err = GetVersion(f)
+
+		require.ErrorContains(err, "reading versions from "+f+" is unsupported")
+	})
+
+	t.Run("fails for registered file type", func(t *testing.T) {
+		require := require.New(t)
+		vfs := versionFiles
+		t.Cleanup(func() {
+			versionFiles = vfs
+		})
+		RegisterVersionFile(func(n string) bool { return n == "VERSION" }, func(p string, v string) error {
+			return os.WriteFile(p, []byte(v+"\n"), 0o644)
+		})
+		d := t.TempDir()
+		f := path.Join(d, "VERSION")
+		err := os.WriteFile(f, []byte("1.0.0\n"), 0o644)
+		require.Nil(err)
+
+		_, err = GetVersion(f)
+
+		require.ErrorContains(err, "is unsupported")
+	})
+}
+
+func TestSetVersion(t *testing.T) {
+	t.Run("sets pyproject.toml", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
 		f := path.Join(d, "pyproject.toml")
 		m := map[string]any{
 			"project": map[string]any{
@@ -347,4 +954,267 @@ func TestSetVersion(t *testing.T) {
 
 		require.ErrorContains(err, "unknown file")
 	})
+
+	t.Run("sets registered file type", func(t *testing.T) {
+		require := require.New(t)
+		vfs := versionFiles
+		t.Cleanup(func() {
+			versionFiles = vfs
+		})
+		RegisterVersionFile(func(n string) bool { return n == "VERSION" }, func(p string, v string) error {
+			return os.WriteFile(p, []byte(v+"\n"), 0o644)
+		})
+		d := t.TempDir()
+		f := path.Join(d, "VERSION")
+		err := os.WriteFile(f, []byte("0.0.0\n"), 0o644)
+		require.Nil(err)
+
+		err = SetVersion("1.0.0", f)
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("1.0.0\n", string(b))
+	})
+
+	t.Run("creates pyproject.toml", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "pyproject.toml")
+
+		err := SetVersionWithOpts("1.0.0", f, &SetVersionOpts{Create: true})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		m := map[string]any{}
+		err = toml.Unmarshal(b, &m)
+		require.Nil(err)
+		require.Equal(map[string]any{"project": map[string]any{"version": "1.0.0"}}, m)
+	})
+
+	t.Run("creates package.json", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+
+		err := SetVersionWithOpts("1.0.0", f, &SetVersionOpts{Create: true})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal(`{"version":"1.0.0"}`, string(b))
+	})
+
+	t.Run("creates Dockerfile", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+
+		err := SetVersionWithOpts("1.0.0", f, &SetVersionOpts{Create: true})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("ARG VERSION=1.0.0\n", string(b))
+	})
+
+	t.Run("creates Dockerfile with directive and key", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "app.Dockerfile")
+
+		err := SetVersionWithOpts("1.0.0", f, &SetVersionOpts{Create: true, DockerfileDirective: "LABEL", DockerfileKey: "org.opencontainers.image.version"})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("LABEL org.opencontainers.image.version=1.0.0\n", string(b))
+	})
+
+	t.Run("create updates existing file", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+		err := os.WriteFile(f, []byte(`{"name":"test","version":"0.0.0"}`), 0o644)
+		require.Nil(err)
+
+		err = SetVersionWithOpts("1.0.0", f, &SetVersionOpts{Create: true})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal(`{"name":"test","version":"1.0.0"}`, string(b))
+	})
+
+	t.Run("create fails for unknown file type", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "unknown.txt")
+
+		err := SetVersionWithOpts("1.0.0", f, &SetVersionOpts{Create: true})
+
+		require.ErrorContains(err, "unknown file")
+		_, err = os.Stat(f)
+		require.ErrorIs(err, os.ErrNotExist)
+	})
+
+	t.Run("sets Dockerfile arg", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("# comment\nFROM alpine AS base\narg VERSION=0.0.0\nFROM base\nARG OTHER=a VERSION\nRUN echo $VERSION\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersion("1.0.0", f)
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("# comment\nFROM alpine AS base\narg VERSION=1.0.0\nFROM base\nARG OTHER=a VERSION=1.0.0\nRUN echo $VERSION\n", string(b))
+	})
+
+	t.Run("sets Dockerfile label", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "app.Dockerfile")
+		err := os.WriteFile(f, []byte("FROM alpine\nLABEL org.opencontainers.image.version=\"0.0.0\" org.opencontainers.image.title=\"app\"\nARG VERSION=0.0.0\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersionWithOpts("1.0.0", f, &SetVersionOpts{DockerfileDirective: "LABEL", DockerfileKey: "org.opencontainers.image.version"})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("FROM alpine\nLABEL org.opencontainers.image.version=\"1.0.0\" org.opencontainers.image.title=\"app\"\nARG VERSION=0.0.0\n", string(b))
+	})
+
+	t.Run("ignores Dockerfile key within quoted values", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("LABEL description=\"bump VERSION here\" VERSION='0.0.0'\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersionWithOpts("1.0.0", f, &SetVersionOpts{DockerfileDirective: "LABEL"})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("LABEL description=\"bump VERSION here\" VERSION='1.0.0'\n", string(b))
+	})
+
+	t.Run("fails for Dockerfile key only within quoted values", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("LABEL description=\"bump VERSION here\"\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersionWithOpts("1.0.0", f, &SetVersionOpts{DockerfileDirective: "LABEL"})
+
+		require.ErrorContains(err, "LABEL VERSION not found")
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("LABEL description=\"bump VERSION here\"\n", string(b))
+	})
+
+	t.Run("sets Dockerfile label within line continuation", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("LABEL a=b \\\n    # comment\n    VERSION=\"0.0.0\"\nRUN VERSION=0.0.0\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersionWithOpts("1.0.0", f, &SetVersionOpts{DockerfileDirective: "LABEL"})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("LABEL a=b \\\n    # comment\n    VERSION=\"1.0.0\"\nRUN VERSION=0.0.0\n", string(b))
+	})
+
+	t.Run("ignores Dockerfile directive within other instruction's line continuation", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("RUN echo \\\n  ARG VERSION=1\nARG VERSION=0.0.0\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersion("2.0.0", f)
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal("RUN echo \\\n  ARG VERSION=1\nARG VERSION=2.0.0\n", string(b))
+	})
+
+	t.Run("fails for Dockerfile directive only within other instruction's line continuation", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("RUN echo \\\n  ARG VERSION=1\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersion("2.0.0", f)
+
+		require.ErrorContains(err, "ARG VERSION not found")
+	})
+
+	t.Run("fails for Dockerfile without directive", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "Dockerfile")
+		err := os.WriteFile(f, []byte("FROM alpine\nARG VERSIONS=0.0.0\n"), 0o755)
+		require.Nil(err)
+
+		err = SetVersion("1.0.0", f)
+
+		require.ErrorContains(err, "ARG VERSION not found")
+	})
+
+	t.Run("sets prerelease and metadata versions", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+		err := os.WriteFile(f, []byte(`{"version": "0.0.0"}`), 0o755)
+		require.Nil(err)
+
+		err = SetVersion("1.0.0-rc.1+build.5", f)
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal(`{"version":"1.0.0-rc.1+build.5"}`, string(b))
+	})
+
+	t.Run("fails for invalid version", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+		err := os.WriteFile(f, []byte(`{"version": "0.0.0"}`), 0o755)
+		require.Nil(err)
+
+		err = SetVersion("1.0", f)
+
+		require.ErrorContains(err, "invalid version string 1.0")
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal(`{"version": "0.0.0"}`, string(b))
+	})
+
+	t.Run("sets invalid version when allowed", func(t *testing.T) {
+		require := require.New(t)
+		d := t.TempDir()
+		f := path.Join(d, "package.json")
+		err := os.WriteFile(f, []byte(`{"version": "0.0.0"}`), 0o755)
+		require.Nil(err)
+
+		err = SetVersionWithOpts("latest", f, &SetVersionOpts{AllowInvalid: true})
+
+		require.Nil(err)
+		b, err := os.ReadFile(f)
+		require.Nil(err)
+		require.Equal(`{"version":"latest"}`, string(b))
+	})
 }
@@ -7,30 +7,63 @@ import (
 
 // A Config represents the entire configuration object used to configure versionctl behavior.
 type Config struct {
-	BreakingChangeTags []string          `json:"breakingChangeTags"`
-	Parser             string            `json:"parser"`
-	Rules              []Rule            `json:"rules"`
-	Tags               map[string]string `json:"tags"`
+	BreakingChangeTags []string                 `json:"breakingChangeTags"`
+	BuildOnUnchanged   bool                     `json:"buildOnUnchanged"`
+	ChainMode          string                   `json:"chainMode"`
+	ExcludeAuthors     []string                 `json:"excludeAuthors"`
+	ExcludeTrailers    []string                 `json:"excludeTrailers"`
+	Formats            map[string]FormatProfile `json:"formats"`
+	IncludeTags        []string                 `json:"includeTags"`
+	MatchType          bool                     `json:"matchType"`
+	Parser             string                   `json:"parser"`
+	Parsers            []ParserSpec             `json:"parsers"`
+	PathFilter         []string                 `json:"pathFilter"`
+	PrereleaseStart    *int                     `json:"prereleaseStart"`
+	Rules              []Rule                   `json:"rules"`
+	Scheme             string                   `json:"scheme"`
+	SkipMarkers        []string                 `json:"skipMarkers"`
+	Strict             bool                     `json:"strict"`
+	TagPrefix          string                   `json:"tagPrefix"`
+	TagRemote          string                   `json:"tagRemote"`
+	Tags               map[string]string        `json:"tags"`
+	UseNotes           bool                     `json:"useNotes"`
+	ZeroMajorSemantics bool                     `json:"zeroMajorSemantics"`
 }
 
 // Options provided to the entry point [New].
 type Opts struct {
-	Config *Config
-	Logger *slog.Logger
+	Config    *Config
+	Logger    *slog.Logger
+	Transform func(Version) (Version, error) // see [AnalyzerOpts.Transform]
 }
 
 // Entry point of the application.
 // Initializes subcomponents, returns [Analyzer].
 // Returns an error if any subcomponents fail to initialize.
 func New(o *Opts) (*Analyzer, error) {
-	l := o.Logger
+	a, err := NewAnalyzerFromConfig(o.Config, o.Logger)
+	if err != nil {
+		return nil, err
+	}
+	a.transform = o.Transform
+	return a, nil
+}
+
+// Convenience constructor that creates an [Analyzer] (and its subcomponents) from a [Config].
+// Uses the process' current working directory as the local working copy.
+// Use [NewAnalyzer] directly to provide custom subcomponents.
+func NewAnalyzerFromConfig(c *Config, l *slog.Logger) (*Analyzer, error) {
 	if l == nil {
 		l = slog.New(slog.NewTextHandler(io.Discard, nil))
 	}
-	p, err := NewParser(o.Config.Parser, &ParserOpts{
-		BreakingChangeTags: o.Config.BreakingChangeTags,
+	p, err := NewParser(c.Parser, &ParserOpts{
+		BreakingChangeTags: c.BreakingChangeTags,
+		ChainMode:          c.ChainMode,
 		Logger:             l.With("name", "parser"),
-		Tags:               o.Config.Tags,
+		MatchType:          c.MatchType,
+		Parsers:            c.Parsers,
+		SkipMarkers:        c.SkipMarkers,
+		Tags:               c.Tags,
 	})
 	if err != nil {
 		return nil, err
@@ -42,10 +75,22 @@ func New(o *Opts) (*Analyzer, error) {
 		return nil, err
 	}
 	a, err := NewAnalyzer(&AnalyzerOpts{
-		Git:    g,
-		Logger: l.With("name", "analyzer"),
-		Parser: p,
-		Rules:  o.Config.Rules,
+		BuildOnUnchanged:   c.BuildOnUnchanged,
+		ExcludeAuthors:     c.ExcludeAuthors,
+		ExcludeTrailers:    c.ExcludeTrailers,
+		Git:                g,
+		IncludeTags:        c.IncludeTags,
+		Logger:             l.With("name", "analyzer"),
+		Parser:             p,
+		PathFilter:         c.PathFilter,
+		PrereleaseStart:    c.PrereleaseStart,
+		Rules:              c.Rules,
+		Scheme:             c.Scheme,
+		Strict:             c.Strict,
+		TagPrefix:          c.TagPrefix,
+		TagRemote:          c.TagRemote,
+		UseNotes:           c.UseNotes,
+		ZeroMajorSemantics: c.ZeroMajorSemantics,
 	})
 	if err != nil {
 		return nil, err
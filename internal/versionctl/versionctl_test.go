@@ -0,0 +1,116 @@
+package versionctl
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Helper method to create a git repository and change the working directory into it.
+func chdirGitRepo(t testing.TB) *TestRepo {
+	t.Helper()
+	require := require.New(t)
+	wd, err := os.Getwd()
+	require.Nil(err)
+	d, r := createGitRepo(t)
+	err = os.Chdir(d)
+	require.Nil(err)
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+	r.createGitCommit("initial")
+	return r
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("fields reach consumers", func(t *testing.T) {
+		require := require.New(t)
+		chdirGitRepo(t)
+		b := []byte(`{
+			"breakingChangeTags": ["bct:"],
+			"buildOnUnchanged": true,
+			"excludeAuthors": ["bot"],
+			"excludeTrailers": ["Skip-Release:"],
+			"includeTags": ["^v"],
+			"matchType": true,
+			"parser": "default",
+			"pathFilter": ["foo/**"],
+			"prereleaseStart": 0,
+			"rules": [{"branch": "main", "prereleaseToken": "rc", "buildMetadata": "meta", "minVersion": "1.0.0", "maxChange": "minor"}],
+			"scheme": "calver",
+			"skipMarkers": ["[skip release]"],
+			"strict": true,
+			"tagPrefix": "foo/v",
+			"tagRemote": "origin",
+			"tags": {"tag:": "minor"},
+			"useNotes": true,
+			"zeroMajorSemantics": true
+		}`)
+		cfg := &Config{}
+		err := json.Unmarshal(b, cfg)
+		require.Nil(err)
+
+		a, err := New(&Opts{Config: cfg})
+		require.Nil(err)
+
+		p, ok := a.parser.(*defaultParser)
+		require.True(ok)
+		require.Equal([]string{"bct:"}, p.breakingChangeTags)
+		require.True(p.matchType)
+		require.Equal([]string{"[skip release]"}, p.skipMarkers)
+		require.Equal(map[string]string{"tag:": "minor"}, p.tags)
+		require.True(a.buildOnUnchanged)
+		require.Equal(1, len(a.excludeAuthors))
+		require.Equal("bot", a.excludeAuthors[0].String())
+		require.Equal([]string{"Skip-Release:"}, a.excludeTrailers)
+		require.Equal(1, len(a.includeTags))
+		require.Equal("^v", a.includeTags[0].String())
+		require.Equal(1, len(a.pathFilter))
+		require.True(a.pathFilter[0].MatchString("foo/a.txt"))
+		require.Equal(0, a.prereleaseStart)
+		require.Equal("calver", a.scheme)
+		require.True(a.strict)
+		require.Equal("foo/v", a.tagPrefix)
+		require.Equal("origin", a.tagRemote)
+		require.Equal([]Rule{{Branch: "main", PrereleaseToken: "rc", Metadata: "meta", MinVersion: "1.0.0", MaxChange: "minor"}}, a.rules)
+		require.True(a.useNotes)
+		require.True(a.zeroMajorSemantics)
+	})
+
+	t.Run("chain parser fields reach consumers", func(t *testing.T) {
+		require := require.New(t)
+		chdirGitRepo(t)
+		b := []byte(`{
+			"chainMode": "first",
+			"parser": "chain",
+			"parsers": [{"parser": "default", "breakingChangeTags": ["bct:"], "tags": {"tag:": "minor"}}]
+		}`)
+		cfg := &Config{}
+		err := json.Unmarshal(b, cfg)
+		require.Nil(err)
+
+		a, err := New(&Opts{Config: cfg})
+		require.Nil(err)
+
+		p, ok := a.parser.(*chainParser)
+		require.True(ok)
+		require.Equal("first", p.mode)
+		require.Equal(1, len(p.parsers))
+		sp, ok := p.parsers[0].(*defaultParser)
+		require.True(ok)
+		require.Equal([]string{"bct:"}, sp.breakingChangeTags)
+		require.Equal(map[string]string{"tag:": "minor"}, sp.tags)
+	})
+
+	t.Run("default config", func(t *testing.T) {
+		require := require.New(t)
+		cfg := &Config{}
+
+		err := json.Unmarshal(DefaultConfig, cfg)
+
+		require.Nil(err)
+		require.Equal(Rule{Branch: "^(?P<branch>.*)$", PrereleaseToken: "alpha", Metadata: "{branch}"}, cfg.Rules[2])
+	})
+}